@@ -0,0 +1,206 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+const (
+	aggFuncSum   = "sum"
+	aggFuncAvg   = "avg"
+	aggFuncMin   = "min"
+	aggFuncMax   = "max"
+	aggFuncCount = "count"
+)
+
+// Aggregate 描述一个聚合表达式，比如 sum(total_amount) as total，通过 Sum、Avg、Min、Max、CountDistinct、CountAll 函数创建
+type Aggregate struct {
+	fn       string
+	column   string
+	alias    string
+	distinct bool
+}
+
+// Sum 生成 sum(column) as alias
+func Sum(column string, alias string) *Aggregate {
+	return &Aggregate{fn: aggFuncSum, column: column, alias: alias}
+}
+
+// Avg 生成 avg(column) as alias
+func Avg(column string, alias string) *Aggregate {
+	return &Aggregate{fn: aggFuncAvg, column: column, alias: alias}
+}
+
+// Min 生成 min(column) as alias
+func Min(column string, alias string) *Aggregate {
+	return &Aggregate{fn: aggFuncMin, column: column, alias: alias}
+}
+
+// Max 生成 max(column) as alias
+func Max(column string, alias string) *Aggregate {
+	return &Aggregate{fn: aggFuncMax, column: column, alias: alias}
+}
+
+// CountDistinct 生成 count(distinct column) as alias
+func CountDistinct(column string, alias string) *Aggregate {
+	return &Aggregate{fn: aggFuncCount, column: column, alias: alias, distinct: true}
+}
+
+// CountAll 生成 count(*) as alias, 一般用于分组统计每组的记录数
+func CountAll(alias string) *Aggregate {
+	return &Aggregate{fn: aggFuncCount, column: "*", alias: alias}
+}
+
+// Alias 返回聚合结果的别名，也是 QueryAggregate 返回的 map 中的 key
+func (agg *Aggregate) Alias() string {
+	return agg.alias
+}
+
+func (agg *Aggregate) toSQL() string {
+	if agg.distinct {
+		return agg.fn + "(distinct " + agg.column + ") as " + agg.alias
+	}
+	return agg.fn + "(" + agg.column + ") as " + agg.alias
+}
+
+// QueryAggregate 执行分组聚合查询，表达 select g1,g2,sum(x) as s from tab where ... group by g1,g2 having ... order by ... 的语义，
+// 每行数据以 map 返回，key 是分组字段名或者聚合表达式的别名，driver 返回的 []byte 值转换成 string，其他值保持 driver 返回的类型，
+// 需要按照数据库类型转换(比如 DECIMAL 转换成 decimal.Decimal)时可以使用 QueryMaps 执行 BuildAggregate 生成的sql。
+//
+// m 是 where 条件，可以为 nil; groupBy 是分组字段，可以为 nil，表示整表聚合; having 是 having 条件，可以为 nil，其中的字段可以是分组字段或者聚合别名;
+// orders 中的字段也可以是分组字段或者聚合别名。
+//
+// 表名通过 GetTableName 确定，支持分表
+func QueryAggregate[T any](tc *TransContext, meta *TableMeta[T], m Matcher, groupBy []string, aggregates []*Aggregate, having Matcher, orders ...*Order) ([]map[string]any, error) {
+	stmt, args, err := aggregateQuery(meta, tc.ctx, m, groupBy, aggregates, having, orders)
+	if err != nil {
+		return nil, err
+	}
+	var table *ResultTable
+	err = queryRowsCore(tc, stmt, args, func(rows *sql.Rows) error {
+		var err error
+		table, err = scanResultTable(rows, bytesAsStringValue)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return table.toMaps(), nil
+}
+
+// BuildAggregate 生成 QueryAggregate 执行的sql及参数，与其他 BuildXxx 函数一样不计算分表
+func BuildAggregate[T any](meta *TableMeta[T], m Matcher, groupBy []string, aggregates []*Aggregate, having Matcher, orders ...*Order) (string, []any, error) {
+	return aggregateQuery(unshardedMeta(meta), context.Background(), m, groupBy, aggregates, having, orders)
+}
+
+// bytesAsStringValue 只把 []byte 转换成 string，其他值保持不变
+func bytesAsStringValue(typeName string, value any) (any, error) {
+	if b, ok := value.([]byte); ok {
+		return string(b), nil
+	}
+	return value, nil
+}
+
+// QueryAggregateInto 与 QueryAggregate 相同，但每行数据填充到调用者提供的 D 类型的对象中，
+// extract 返回的 field 指针顺序必须是: 先是 groupBy 中的分组字段，然后是 aggregates 中的聚合表达式
+func QueryAggregateInto[T any, D any](tc *TransContext, meta *TableMeta[T], m Matcher, groupBy []string, aggregates []*Aggregate, having Matcher, extract ExtractScanFieldPoints[D], orders ...*Order) ([]*D, error) {
+	stmt, args, err := aggregateQuery(meta, tc.ctx, m, groupBy, aggregates, having, orders)
+	if err != nil {
+		return nil, err
+	}
+	return QueryRawSQL(tc, extract, stmt, args...)
+}
+
+func aggregateQuery[T any](meta *TableMeta[T], ctx context.Context, matcher Matcher, groupBy []string, aggregates []*Aggregate, having Matcher, orders []*Order) (string, []any, error) {
+	if len(aggregates) == 0 {
+		return "", nil, errors.New("aggregate query needs at least one aggregate")
+	}
+	if err := checkQueryColumns(meta, &View{viewColumns: groupBy, include: true}, matcher, nil); err != nil {
+		return "", nil, err
+	}
+
+	// having 及 order 中可以引用的名字: 分组字段及聚合别名
+	names := map[string]bool{}
+	for _, c := range groupBy {
+		names[c] = true
+	}
+	selects := append([]string{}, groupBy...)
+	for _, agg := range aggregates {
+		if StrictColumnCheck && agg.column != "*" {
+			if err := checkColumnList(meta, "aggregate", []string{agg.column}); err != nil {
+				return "", nil, err
+			}
+		}
+		if !isSimpleIdentifier(agg.alias) {
+			return "", nil, errors.New("invalid aggregate alias " + agg.alias)
+		}
+		names[agg.alias] = true
+		selects = append(selects, agg.toSQL())
+	}
+	if StrictColumnCheck {
+		if err := checkAggregateNames(meta, names, having, orders); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("select ")
+	builder.WriteString(strings.Join(selects, ","))
+	builder.WriteString(" from ")
+	builder.WriteString(GetTableName(ctx, meta))
+
+	var args []any
+	if matcher != nil {
+		condi, a, err := matcher.ToSQL(args)
+		if err != nil {
+			return "", nil, err
+		}
+		if condi != "" {
+			builder.WriteString(" where ")
+			builder.WriteString(condi)
+			args = a
+		}
+	}
+	if len(groupBy) > 0 {
+		builder.WriteString(" group by ")
+		builder.WriteString(strings.Join(groupBy, ","))
+	}
+	if having != nil {
+		condi, a, err := having.ToSQL(args)
+		if err != nil {
+			return "", nil, err
+		}
+		if condi != "" {
+			builder.WriteString(" having ")
+			builder.WriteString(condi)
+			args = a
+		}
+	}
+	builder.WriteString(buildQuerySuffix(nil, orders))
+	return builder.String(), args, nil
+}
+
+// checkAggregateNames 严格模式下校验 having 和 order 中引用的名字，它们可以是表字段，也可以是分组字段或聚合别名
+func checkAggregateNames[T any](meta *TableMeta[T], names map[string]bool, having Matcher, orders []*Order) error {
+	var refers []string
+	if referrer, ok := having.(columnsReferrer); ok {
+		refers = referrer.referColumns(refers)
+	}
+	for _, order := range orders {
+		refers = append(refers, order.ColumnName)
+	}
+	for _, name := range refers {
+		if names[name] {
+			continue
+		}
+		if err := checkColumnList(meta, "aggregate", []string{name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
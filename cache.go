@@ -0,0 +1,204 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+
+	txrequest "github.com/rolandhe/daog/tx"
+)
+
+// Cache 表级别的二级缓存接口，通过 TableMeta.Cache 为表开启，缺省为nil，表示不缓存。
+// key 由查询生成的sql及参数组成，table 是逻辑表名(TableMeta.Table)，当表上的 Insert/Update/UpdateByModifier/Delete* 提交成功后，
+// 会调用 InvalidateTable 使该表的所有缓存失效。实现必须是并发安全的。
+//
+// 注意，ExecRawSQL、ExecNamedSQL 执行的sql不会使缓存失效，通过它们修改了开启缓存的表后需要自行调用 InvalidateTable
+type Cache interface {
+	Get(table string, key string) (any, bool)
+	Set(table string, key string, value any)
+	// Token 返回表当前的版本标识，每次 InvalidateTable 后都会变化
+	Token(table string) uint64
+	// SetIfToken 只有表的版本标识仍然等于 token 时才写入缓存，避免查询数据库期间表被修改，把旧数据写入缓存
+	SetIfToken(table string, key string, value any, token uint64)
+	InvalidateTable(table string)
+}
+
+// LRUCache 内存中的 LRU 缓存，超过容量时淘汰最久未使用的数据，每条数据在 ttl 后过期
+type LRUCache struct {
+	capacity    int
+	ttl         time.Duration
+	lock        sync.Mutex
+	ll          *list.List
+	items       map[string]*list.Element
+	generations map[string]uint64
+}
+
+type lruEntry struct {
+	key        string
+	table      string
+	generation uint64
+	value      any
+	expireAt   time.Time
+}
+
+// NewLRUCache 创建 LRUCache，capacity 是最多缓存的条数，小于等于0时不缓存任何数据，ttl 是缓存数据的有效期，0 表示不过期
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	return &LRUCache{
+		capacity:    capacity,
+		ttl:         ttl,
+		ll:          list.New(),
+		items:       map[string]*list.Element{},
+		generations: map[string]uint64{},
+	}
+}
+
+func (c *LRUCache) Get(table string, key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ele, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := ele.Value.(*lruEntry)
+	if entry.generation != c.generations[table] || (c.ttl > 0 && time.Now().After(entry.expireAt)) {
+		c.removeElement(ele)
+		return nil, false
+	}
+	c.ll.MoveToFront(ele)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(table string, key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.set(table, key, value)
+}
+
+func (c *LRUCache) Token(table string) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generations[table]
+}
+
+func (c *LRUCache) SetIfToken(table string, key string, value any, token uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.generations[table] != token {
+		return
+	}
+	c.set(table, key, value)
+}
+
+func (c *LRUCache) set(table string, key string, value any) {
+	if c.capacity <= 0 {
+		return
+	}
+	entry := &lruEntry{
+		key:        key,
+		table:      table,
+		generation: c.generations[table],
+		value:      value,
+		expireAt:   time.Now().Add(c.ttl),
+	}
+	if ele, ok := c.items[key]; ok {
+		ele.Value = entry
+		c.ll.MoveToFront(ele)
+		return
+	}
+	c.items[key] = c.ll.PushFront(entry)
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// InvalidateTable 使表的所有缓存失效，失效的数据在被访问或者被淘汰时删除
+func (c *LRUCache) InvalidateTable(table string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generations[table]++
+}
+
+func (c *LRUCache) removeElement(ele *list.Element) {
+	c.ll.Remove(ele)
+	delete(c.items, ele.Value.(*lruEntry).key)
+}
+
+// queryWithCache 如果表开启了缓存，先从缓存中读取，未命中时通过 load 从数据库读取并写入缓存。
+// 当前事务已经修改过该表时不使用缓存，避免读到旧数据或者把未提交的数据写入缓存。只有 txrequest.RequestNone 时才写入缓存，
+// 事务内读到的是事务开始时的快照，可能比其他已经提交的修改更旧。load 之前读取表的版本标识，如果 load 期间表被修改则放弃写入。
+// 缓存中的对象不会被返回，返回的都是拷贝
+func queryWithCache[T any](tc *TransContext, meta *TableMeta[T], sql string, args []any, load func() ([]*T, error)) ([]*T, error) {
+	if meta.Cache == nil || tc.dirtyCaches[meta.Table] != nil {
+		return load()
+	}
+	key, err := cacheKey(sql, args)
+	if err != nil {
+		return load()
+	}
+	if value, ok := meta.Cache.Get(meta.Table, key); ok {
+		if cached, ok := value.([]*T); ok {
+			return copyCachedList(meta, cached), nil
+		}
+	}
+	if tc.txRequest != txrequest.RequestNone {
+		return load()
+	}
+	token := meta.Cache.Token(meta.Table)
+	ret, err := load()
+	if err != nil {
+		return nil, err
+	}
+	meta.Cache.SetIfToken(meta.Table, key, copyCachedList(meta, ret), token)
+	return ret, nil
+}
+
+// cacheKey 由 sql 及经过 formatArgs 转换后参数的 json 组成，time.Time 按照 RFC3339Nano 格式输出，包含时区，
+// 不同时区的相同钟点不会共用缓存
+func cacheKey(sql string, args []any) (string, error) {
+	values, err := json.Marshal(formatArgs(args))
+	if err != nil {
+		return "", err
+	}
+	return sql + "\n" + string(values), nil
+}
+
+// copyCachedList 拷贝每个对象，[]byte 类型的字段也会被拷贝，修改返回的对象不会影响缓存
+func copyCachedList[T any](meta *TableMeta[T], src []*T) []*T {
+	ret := make([]*T, len(src))
+	for i, p := range src {
+		v := *p
+		for _, column := range meta.Columns {
+			if b, ok := meta.LookupFieldFunc(column, &v, true).(*[]byte); ok && *b != nil {
+				*b = append([]byte(nil), *b...)
+			}
+		}
+		ret[i] = &v
+	}
+	return ret
+}
+
+// markTableModified 记录 tc 修改了表，txrequest.RequestNone 时每条sql自动提交，立即使缓存失效，否则在事务提交成功后失效
+func markTableModified[T any](tc *TransContext, meta *TableMeta[T]) {
+	if meta.Cache == nil {
+		return
+	}
+	if tc.txRequest == txrequest.RequestNone {
+		meta.Cache.InvalidateTable(meta.Table)
+		return
+	}
+	if tc.dirtyCaches == nil {
+		tc.dirtyCaches = map[string]Cache{}
+	}
+	tc.dirtyCaches[meta.Table] = meta.Cache
+}
+
+func (tc *TransContext) invalidateDirtyCaches() {
+	for table, cache := range tc.dirtyCaches {
+		cache.InvalidateTable(table)
+	}
+	tc.dirtyCaches = nil
+}
@@ -0,0 +1,200 @@
+package daog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rolandhe/daog/ttypes"
+	txrequest "github.com/rolandhe/daog/tx"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2, time.Minute)
+	c.Set("a", "k1", 1)
+	c.Set("a", "k2", 2)
+	if _, ok := c.Get("a", "k1"); !ok {
+		t.Error("k1 should be cached")
+	}
+	c.Set("b", "k3", 3)
+	if _, ok := c.Get("a", "k2"); ok {
+		t.Error("k2 should be evicted")
+	}
+
+	c.InvalidateTable("a")
+	if _, ok := c.Get("a", "k1"); ok {
+		t.Error("k1 should be invalidated")
+	}
+	if v, ok := c.Get("b", "k3"); !ok || v != 3 {
+		t.Error("k3 should survive invalidation of another table")
+	}
+
+	c = NewLRUCache(2, time.Millisecond)
+	c.Set("a", "k1", 1)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("a", "k1"); ok {
+		t.Error("k1 should expire")
+	}
+}
+
+func TestLRUCacheToken(t *testing.T) {
+	c := NewLRUCache(2, 0)
+	token := c.Token("a")
+	c.InvalidateTable("a")
+	c.SetIfToken("a", "k1", 1, token)
+	if _, ok := c.Get("a", "k1"); ok {
+		t.Error("stale token should not be cached")
+	}
+	c.SetIfToken("a", "k1", 1, c.Token("a"))
+	if _, ok := c.Get("a", "k1"); !ok {
+		t.Error("current token should be cached")
+	}
+
+	c = NewLRUCache(0, 0)
+	c.Set("a", "k1", 1)
+	if _, ok := c.Get("a", "k1"); ok {
+		t.Error("zero capacity should not cache")
+	}
+}
+
+func TestQueryWithCache(t *testing.T) {
+	meta := *filterSampleMeta
+	meta.Cache = NewLRUCache(10, 0)
+	loads := 0
+	load := func() ([]*filterSample, error) {
+		loads++
+		return []*filterSample{{Id: 1, Name: "tom"}}, nil
+	}
+	stmt := "select * from filter_sample where id=?"
+	clean := &TransContext{txRequest: txrequest.RequestNone, status: tcStatusInit, ctx: context.Background()}
+
+	first, _ := queryWithCache(clean, &meta, stmt, []any{1}, load)
+	second, _ := queryWithCache(clean, &meta, stmt, []any{1}, load)
+	if loads != 1 || second[0].Name != "tom" || first[0] == second[0] {
+		t.Error("second query should hit cache with a copy", loads)
+	}
+	second[0].Name = "changed"
+	if third, _ := queryWithCache(clean, &meta, stmt, []any{1}, load); third[0].Name != "tom" {
+		t.Error("cached object should not be shared")
+	}
+
+	tc := &TransContext{txRequest: txrequest.RequestWrite, status: tcStatusInit, ctx: context.Background()}
+	queryWithCache(tc, &meta, stmt, []any{1}, load)
+	if loads != 1 {
+		t.Error("transaction should read cached data", loads)
+	}
+	queryWithCache(tc, &meta, stmt, []any{3}, load)
+	queryWithCache(tc, &meta, stmt, []any{3}, load)
+	if loads != 3 {
+		t.Error("transaction snapshot should not be written to cache", loads)
+	}
+	key, _ := cacheKey(stmt, []any{3})
+	if _, ok := meta.Cache.Get(meta.Table, key); ok {
+		t.Error("transaction snapshot should not be written to cache")
+	}
+
+	markTableModified(tc, &meta)
+	queryWithCache(tc, &meta, stmt, []any{1}, load)
+	if loads != 4 {
+		t.Error("dirty transaction should bypass cache", loads)
+	}
+	key, _ = cacheKey(stmt, []any{1})
+	if _, ok := meta.Cache.Get(meta.Table, key); !ok {
+		t.Error("cache should stay valid before commit")
+	}
+
+	queryWithCache(clean, &meta, stmt, []any{2}, func() ([]*filterSample, error) {
+		meta.Cache.InvalidateTable(meta.Table)
+		return load()
+	})
+	key, _ = cacheKey(stmt, []any{2})
+	if _, ok := meta.Cache.Get(meta.Table, key); ok {
+		t.Error("result loaded across an invalidation should not be cached")
+	}
+
+	meta.Cache.Set(meta.Table, "k", 1)
+	markTableModified(clean, &meta)
+	if _, ok := meta.Cache.Get(meta.Table, "k"); ok {
+		t.Error("auto commit modification should invalidate immediately")
+	}
+}
+
+func TestCacheInvalidationOnComplete(t *testing.T) {
+	meta := *filterSampleMeta
+	meta.Cache = NewLRUCache(10, 0)
+	db := sql.OpenDB(&fakeDB{})
+	defer db.Close()
+
+	for _, commit := range []bool{true, false} {
+		meta.Cache.Set(meta.Table, "k", 1)
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc := &TransContext{txRequest: txrequest.RequestWrite, tx: fakeTx{}, conn: conn, status: tcStatusInit, ctx: context.Background()}
+		markTableModified(tc, &meta)
+		var e error
+		if !commit {
+			e = errors.New("rollback")
+		}
+		tc.Complete(e)
+		if _, ok := meta.Cache.Get(meta.Table, "k"); ok != !commit {
+			t.Error("commit should invalidate and rollback should keep cache", commit)
+		}
+	}
+}
+
+func TestCacheKeyTimeZone(t *testing.T) {
+	stmt := "select * from filter_sample where create_at > ?"
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	shanghai := time.FixedZone("CST", 8*3600)
+	k1, err1 := cacheKey(stmt, []any{at})
+	k2, err2 := cacheKey(stmt, []any{time.Date(2024, 1, 2, 3, 4, 5, 0, shanghai)})
+	if err1 != nil || err2 != nil || k1 == k2 {
+		t.Error("same wall clock in different zones should not share a key", k1, k2)
+	}
+	k3, _ := cacheKey(stmt, []any{ttypes.NormalDatetime(at)})
+	if k1 != k3 {
+		t.Error("driver.Valuer args should be keyed by their value", k1, k3)
+	}
+	k4, _ := cacheKey(stmt, []any{"1"})
+	k5, _ := cacheKey(stmt, []any{1})
+	if k4 == k5 {
+		t.Error("string and int args should not share a key")
+	}
+}
+
+type blobSample struct {
+	Id   int64
+	Data []byte
+}
+
+func TestCopyCachedListBytes(t *testing.T) {
+	meta := &TableMeta[blobSample]{
+		Table:   "blob_sample",
+		Columns: []string{"id", "data"},
+		LookupFieldFunc: func(columnName string, ins *blobSample, point bool) any {
+			switch columnName {
+			case "id":
+				if point {
+					return &ins.Id
+				}
+				return ins.Id
+			case "data":
+				if point {
+					return &ins.Data
+				}
+				return ins.Data
+			}
+			return nil
+		},
+	}
+	src := []*blobSample{{Id: 1, Data: []byte("abc")}, {Id: 2}}
+	copied := copyCachedList(meta, src)
+	copied[0].Data[0] = 'x'
+	if string(src[0].Data) != "abc" || copied[1].Data != nil {
+		t.Error(string(src[0].Data), copied[1].Data)
+	}
+}
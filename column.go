@@ -0,0 +1,86 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+// Column 带类型的表字段描述，T 是表实体的类型，V 是该字段在 T 中对应 field 的类型。
+// compile 不会生成 Column，需要使用者在 compile 生成的 xx-ext.go 中手写一个包含所有字段 Column 的变量，比如 example/dal/GroupInfo-ext.go 中的 GroupInfoCols，
+// 表结构变化时需要同步修改。通过它生成条件、排序及修改项时，值的类型在编译期就会被检查，比如 GroupInfoCols.CreateAt.Eq("oops") 无法通过编译
+type Column[T any, V any] struct {
+	name string
+}
+
+// NewColumn 创建 Column，name 是数据库表的字段名，一般只在 xx-ext.go 中定义 XxxCols 时使用
+func NewColumn[T any, V any](name string) Column[T, V] {
+	return Column[T, V]{name: name}
+}
+
+// Name 返回数据库表的字段名
+func (c Column[T, V]) Name() string {
+	return c.name
+}
+
+// Eq 生成 column = ? 条件, 可以通过 Matcher.AddCond 加入到 Matcher
+func (c Column[T, V]) Eq(value V) SQLCond {
+	return newEqCond(c.name, value)
+}
+
+// Ne 生成 column != ? 条件
+func (c Column[T, V]) Ne(value V) SQLCond {
+	return newNeCond(c.name, value)
+}
+
+// Lt 生成 column < ? 条件
+func (c Column[T, V]) Lt(value V) SQLCond {
+	return newLtCond(c.name, value)
+}
+
+// Lte 生成 column <= ? 条件
+func (c Column[T, V]) Lte(value V) SQLCond {
+	return newLteCond(c.name, value)
+}
+
+// Gt 生成 column > ? 条件
+func (c Column[T, V]) Gt(value V) SQLCond {
+	return newGtCond(c.name, value)
+}
+
+// Gte 生成 column >= ? 条件
+func (c Column[T, V]) Gte(value V) SQLCond {
+	return newGteCond(c.name, value)
+}
+
+// In 生成 column in (?,?,...) 条件
+func (c Column[T, V]) In(values ...V) SQLCond {
+	return newInCond(c.name, ConvertToAnySlice(values))
+}
+
+// NotIn 生成 column not in (?,?,...) 条件
+func (c Column[T, V]) NotIn(values ...V) SQLCond {
+	return newNotInCond(c.name, ConvertToAnySlice(values))
+}
+
+// Between 生成 column between ? and ? 条件
+func (c Column[T, V]) Between(start V, end V) SQLCond {
+	return newBetweenCond(c.name, start, end)
+}
+
+// Null 生成 column is null 条件，not 为 true 时生成 column is not null
+func (c Column[T, V]) Null(not bool) SQLCond {
+	return newNullCond(c.name, not)
+}
+
+// Asc 生成按该字段升序的排序条件
+func (c Column[T, V]) Asc() *Order {
+	return NewOrder(c.name)
+}
+
+// Desc 生成按该字段降序的排序条件
+func (c Column[T, V]) Desc() *Order {
+	return NewDescOrder(c.name)
+}
+
+// Set 在 modifier 中增加一个该字段的修改项，表达 set column = ? 语义
+func (c Column[T, V]) Set(modifier Modifier, value V) Modifier {
+	return modifier.Add(c.name, value)
+}
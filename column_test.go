@@ -0,0 +1,48 @@
+package daog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumn(t *testing.T) {
+	id := NewColumn[filterSample, int64]("id")
+	name := NewColumn[filterSample, string]("name")
+	status := NewColumn[filterSample, int32]("status")
+
+	cases := []struct {
+		cond SQLCond
+		sql  string
+		args []any
+	}{
+		{id.Eq(1), "id = ?", []any{int64(1)}},
+		{name.Ne("a"), "name != ?", []any{"a"}},
+		{id.Lt(1), "id < ?", []any{int64(1)}},
+		{id.Lte(1), "id <= ?", []any{int64(1)}},
+		{id.Gt(1), "id > ?", []any{int64(1)}},
+		{id.Gte(1), "id >= ?", []any{int64(1)}},
+		{status.In(1, 2), "status in (?,?)", []any{int32(1), int32(2)}},
+		{status.NotIn(3), "status not in (?)", []any{int32(3)}},
+		{id.Between(1, 9), "id between ? and ?", []any{int64(1), int64(9)}},
+		{name.Null(false), "name is null", nil},
+		{name.Null(true), "name is not null", nil},
+	}
+	for i, c := range cases {
+		sql, args, err := c.cond.ToSQL(nil)
+		if err != nil || sql != c.sql || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("case %d: %s %v %v", i, sql, args, err)
+		}
+	}
+
+	m := NewMatcher().AddCond(status.Eq(1)).AddCond(name.Null(true))
+	sql, args, err := BuildSelect(filterSampleMeta, m, nil, []*Order{id.Desc(), name.Asc()}, NewView([]string{id.Name()}))
+	if err != nil || sql != "select id from filter_sample where status = ? and name is not null order by id desc,name" || !reflect.DeepEqual(args, []any{int32(1)}) {
+		t.Error(sql, args, err)
+	}
+
+	modifier := name.Set(NewModifier(), "tom")
+	sql, args, err = BuildUpdate(filterSampleMeta, status.Set(modifier, 2), NewMatcher().AddCond(id.Eq(7)))
+	if err != nil || sql != "update filter_sample set name=?,status=? where id = ?" || !reflect.DeepEqual(args, []any{"tom", int32(2), int64(7)}) {
+		t.Error(sql, args, err)
+	}
+}
@@ -0,0 +1,78 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StrictColumnCheck 是否开启严格的字段校验，开启后， Matcher、 Order、 View 以及 Modifier 中引用的字段在生成sql之前都会与 TableMeta.Columns 比对，
+// 不是表字段的会返回 ErrUnknownColumn 错误，这可以防止把客户端传入的排序字段等直接拼接到sql中带来的sql注入风险。
+//
+// 注意: AddScalar 添加的标量条件以及自行实现的 SQLCond 无法被校验
+var StrictColumnCheck = false
+
+// ErrUnknownColumn 严格字段校验模式下，引用的字段不是表字段时返回的错误，可以使用 errors.Is 判断
+var ErrUnknownColumn = errors.New("unknown column")
+
+// columnsReferrer 能够报告自身引用了哪些表字段的条件，内置的条件都实现了该接口
+type columnsReferrer interface {
+	// referColumns 把引用的字段追加到 columns 并返回
+	referColumns(columns []string) []string
+}
+
+// HasColumn 判断 column 是否是表的字段
+func (meta *TableMeta[T]) HasColumn(column string) bool {
+	for _, c := range meta.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func checkQueryColumns[T any](meta *TableMeta[T], view *View, matcher SQLCond, orders []*Order) error {
+	if !StrictColumnCheck {
+		return nil
+	}
+	if view != nil {
+		if err := checkColumnList(meta, "view", view.viewColumns); err != nil {
+			return err
+		}
+	}
+	for _, order := range orders {
+		if err := checkColumnList(meta, "order", []string{order.ColumnName}); err != nil {
+			return err
+		}
+	}
+	return checkMatcherColumns(meta, matcher)
+}
+
+func checkMatcherColumns[T any](meta *TableMeta[T], matcher SQLCond) error {
+	if !StrictColumnCheck || matcher == nil {
+		return nil
+	}
+	referrer, ok := matcher.(columnsReferrer)
+	if !ok {
+		return nil
+	}
+	return checkColumnList(meta, "matcher", referrer.referColumns(nil))
+}
+
+func checkModifierColumns[T any](meta *TableMeta[T], modifier Modifier) error {
+	if !StrictColumnCheck || modifier == nil {
+		return nil
+	}
+	return checkColumnList(meta, "modifier", modifier.columns())
+}
+
+func checkColumnList[T any](meta *TableMeta[T], usage string, columns []string) error {
+	for _, column := range columns {
+		if !meta.HasColumn(column) {
+			return fmt.Errorf("%w: %s column %q is not a column of table %s", ErrUnknownColumn, usage, column, meta.Table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package daog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictColumnCheck(t *testing.T) {
+	defer func() {
+		StrictColumnCheck = false
+	}()
+	StrictColumnCheck = true
+
+	orMatcher := NewOrMatcher().Eq("id", 1).Add(NewMatcher().Like("nick", "a", LikeStyleAll))
+	unknown := []func() error{
+		func() error {
+			_, _, err := BuildSelect(filterSampleMeta, NewMatcher().Eq("nick", 1), nil, nil, nil)
+			return err
+		},
+		func() error {
+			_, _, err := BuildSelect(filterSampleMeta, orMatcher, nil, nil, nil)
+			return err
+		},
+		func() error {
+			_, _, err := BuildSelect(filterSampleMeta, nil, nil, []*Order{NewOrder("id;drop table t")}, nil)
+			return err
+		},
+		func() error {
+			_, _, err := BuildSelect(filterSampleMeta, nil, nil, nil, NewView([]string{"id", "nick"}))
+			return err
+		},
+		func() error {
+			_, _, err := BuildUpdate(filterSampleMeta, NewModifier().Add("nick", "a"), NewMatcher().Eq("id", 1))
+			return err
+		},
+		func() error {
+			_, _, err := BuildDelete(filterSampleMeta, NewMatcher().TupleIn([]string{"id", "nick"}, [][]any{{1, "a"}}))
+			return err
+		},
+	}
+	for i, fn := range unknown {
+		if err := fn(); !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("case %d: %v", i, err)
+		}
+	}
+
+	m := NewMatcher().Eq("id", 1).Add(NewOrMatcher().Like("name", "a", LikeStyleAll).Between("create_at", "2024-01-01", nil))
+	if _, _, err := BuildSelect(filterSampleMeta, m, NewPager(10, 1), []*Order{NewDescOrder("status")}, NewView([]string{"id", "name"})); err != nil {
+		t.Error(err)
+	}
+	if _, _, err := BuildUpdate(filterSampleMeta, NewModifier().Add("name", "a").SelfAdd("status", 1), m); err != nil {
+		t.Error(err)
+	}
+
+	StrictColumnCheck = false
+	if _, _, err := BuildSelect(filterSampleMeta, NewMatcher().Eq("nick", 1), nil, nil, nil); err != nil {
+		t.Error("non-strict mode should not check columns", err)
+	}
+}
@@ -46,9 +46,50 @@ func newNotInCond(column string, values []any) SQLCond {
 	}
 }
 
+func newTupleInCond(columns []string, rows [][]any) SQLCond {
+	return &tupleInCond{
+		columns: columns,
+		rows:    rows,
+	}
+}
+
+func newTupleNotInCond(columns []string, rows [][]any) SQLCond {
+	return &tupleInCond{
+		columns: columns,
+		rows:    rows,
+		not:     true,
+	}
+}
+
 func newLikeCond(column string, value string, likeStyle int) SQLCond {
 	return &likeCond{
-		column, value, likeStyle,
+		column:    column,
+		value:     value,
+		likeStyle: likeStyle,
+	}
+}
+
+func newLikeEscapeCond(column string, value string, likeStyle int) SQLCond {
+	return &likeCond{
+		column:    column,
+		value:     value,
+		likeStyle: likeStyle,
+		escape:    true,
+	}
+}
+
+func newRegexpCond(column string, pattern string) SQLCond {
+	return &regexpCond{
+		column:  column,
+		pattern: pattern,
+	}
+}
+
+func newCollateEqCond(column string, value any, collation string) SQLCond {
+	return &collateEqCond{
+		column:    column,
+		value:     value,
+		collation: collation,
 	}
 }
 
@@ -5,6 +5,7 @@ package daog
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -20,8 +21,25 @@ const (
 	LikeStyleLeft = 1
 	// LikeStyleRight ,like "value%"
 	LikeStyleRight = 2
+
+	// like 转义模式下使用的转义字符，生成 escape '!'，不使用 \ 是因为 sql_mode 含有 NO_BACKSLASH_ESCAPES 时 '\\' 是两个字符，mysql 会报错
+	likeEscapeChar = '!'
+
+	// mysql 单条语句最多支持 65535 个 ? 占位符
+	maxPlaceholders = 65535
 )
 
+// MaxInSize in 列表允许的最大值个数，0 表示不限制。
+// 超过时 In / NotIn 条件会被拆分成多个以 or (not in 时为 and) 连接的 in 列表，TupleIn 的拆分也使用该值，这种拆分发生在同一条sql内，不会减小语句的大小;
+// GetByIds、UpdateByIds、DeleteByIds 等基于主键列表的函数会把主键按照该大小分批，在同一个 TransContext 内执行多条语句，
+// 当主键数量巨大时，这可以避免超出 max_allowed_packet 或者生成糟糕的执行计划，一般设置为 1000 左右。
+// 分批修改时如果某一批执行失败，tc 的事务类型是 txrequest.RequestNone 时之前批次的修改已经自动提交，返回已修改的记录数及错误，
+// 其他事务类型返回 0 及错误，需要回滚事务
+var MaxInSize = 0
+
+// IgnoreCaseCollation EqIgnoreCase 使用的排序规则(collation)，需要与表字段的字符集匹配
+var IgnoreCaseCollation = "utf8mb4_general_ci"
+
 // NewMatcher 构建一个以 and 连接的匹配条件构建器
 func NewMatcher() Matcher {
 	return NewAndMatcher()
@@ -37,6 +55,17 @@ func NewOrMatcher() Matcher {
 	return &compositeCond{logicOp: logicOpOr}
 }
 
+// NewOptionalMatcher 构建一个以 and 连接的可选条件构建器，适用于搜索表单类的查询: 通过 Eq、Like、Between 等方法添加条件时，
+// 如果条件值为空(由 DefaultEmptyPredicate 判断)，该条件会被忽略，不必再为每个条件写 if 判断
+func NewOptionalMatcher() Matcher {
+	return &compositeCond{logicOp: logicOpAnd, isEmpty: DefaultEmptyPredicate}
+}
+
+// NewOptionalOrMatcher 构建一个以 or 连接的可选条件构建器，与 NewOptionalMatcher 类似
+func NewOptionalOrMatcher() Matcher {
+	return &compositeCond{logicOp: logicOpOr, isEmpty: DefaultEmptyPredicate}
+}
+
 // SQLCond 抽象描述一个sql的条件，可以是 单个字段的条件，比如 name=?, 也可以是通过连接操作符(and/or)连接的多个条件。
 // 每一个条件以 [字段 操作符 值占位符] 的方式组成，比如 id = ?,生成条件时需要传入每个占位符对应一个参数值
 // 也可以直接给一个标量条件，没有参数，比如 status = 0
@@ -76,14 +105,47 @@ type Matcher interface {
 	Gte(column string, value any) Matcher
 
 	// In 快速生成in 条件语义，比如 xx in(?,?,...)
+	// 设置了 MaxInSize 时，超长的 values 会被拆分成多个以 or 连接的 in 列表，但仍然是同一条sql，只能避免单个 in 列表过长，并不减少语句及网络包的大小，
+	// 需要减小语句时请分批执行，GetByIds、UpdateByIds、DeleteByIds 会按照 MaxInSize 分批执行多条语句
 	In(column string, values []any) Matcher
 
 	// NotIn 快速生成 not in 语义，比如 xx not in(?,?,...)
 	NotIn(column string, values []any) Matcher
 
+	// TupleIn 快速生成多字段组合的 in 条件语义，比如 (tenant_id,user_id) in ((?,?),(?,?)), 适用于按联合键批量查询
+	// rows 中每一行值的个数必须与 columns 的个数相同，占位符总数不能超过 mysql 的上限 65535，超过时 ToSQL 返回错误。
+	// 与 In 一样，只有设置了 MaxInSize 时才会拆分成多个以 or 连接的 in 列表，拆分后仍然是同一条sql，
+	// 行数巨大时需要调用方分批执行多条语句，或者使用 GetByKeys/DeleteByKeys，它们会按照 MaxInSize 及占位符上限自动分批
+	TupleIn(columns []string, rows [][]any) Matcher
+
+	// TupleNotIn 快速生成多字段组合的 not in 语义，TupleIn 的反向，拆分后的多个 not in 列表以 and 连接
+	TupleNotIn(columns []string, rows [][]any) Matcher
+
 	// Like 快速生成 like 条件语义， 参数 likeStyle对应 枚举值： LikeStyleAll/ LikeStyleLeft / LikeStyleRight
 	Like(column string, value string, likeStyle int) Matcher
 
+	// LikeEscape 与 Like 类似，但会把 value 中的 %、_ 和转义字符 ! 转义，并显式的生成 escape 子句，比如 name like ? escape '!'，
+	// 适用于 value 来自用户输入的场景，用户搜索 50% 时只会匹配包含 "50%" 的数据
+	LikeEscape(column string, value string, likeStyle int) Matcher
+
+	// StartsWith 以转义模式生成前缀匹配条件，等同于 LikeEscape(column, value, LikeStyleRight)
+	StartsWith(column string, value string) Matcher
+
+	// EndsWith 以转义模式生成后缀匹配条件，等同于 LikeEscape(column, value, LikeStyleLeft)
+	EndsWith(column string, value string) Matcher
+
+	// Contains 以转义模式生成包含匹配条件，等同于 LikeEscape(column, value, LikeStyleAll)
+	Contains(column string, value string) Matcher
+
+	// Regexp 快速生成正则匹配条件语义，比如 name regexp ?
+	Regexp(column string, pattern string) Matcher
+
+	// EqIgnoreCase 快速生成忽略大小写的等于条件语义，比如 name = ? collate utf8mb4_general_ci, 使用的排序规则由 IgnoreCaseCollation 指定
+	EqIgnoreCase(column string, value any) Matcher
+
+	// EqCollate 快速生成按照指定排序规则(collation)比较的等于条件语义，比如 name = ? collate utf8mb4_bin
+	EqCollate(column string, value any, collation string) Matcher
+
 	// Null 快速生成是否为空的条件语义，比如 name is null,  参数not表示是否为not null， 如果为true， 则生成条件 name is not null
 	Null(column string, not bool) Matcher
 
@@ -98,88 +160,170 @@ type Matcher interface {
 	// BitwiseAnd , 位与， a & 1 = 1
 	// 注意 mask, target 类型必须相同，支持 int int8 int16 int32 int64
 	BitwiseAnd(column string, mask, target any) Matcher
+
+	// OptionalBy 指定紧随其后添加的一个条件的判空函数，如果该条件的值为空，则忽略该条件，比如:
+	//
+	//	m.OptionalBy(func(v any) bool { return v.(int) < 0 }).Eq("status", status)
+	//
+	// 它对普通 Matcher 和可选 Matcher 都有效，对于可选 Matcher，它替换掉缺省的判空函数；对于 Between，start 和 end 分别判空，都为空时忽略该条件
+	OptionalBy(isEmpty EmptyPredicate) Matcher
 }
 
 type compositeCond struct {
 	conds   []SQLCond
 	logicOp string
+	// 可选模式下的判空函数，nil 表示非可选模式
+	isEmpty EmptyPredicate
+	// 通过 OptionalBy 指定的判空函数，仅对下一个添加的条件有效
+	nextEmpty EmptyPredicate
+}
+
+// takeEmptyPredicate 返回当前条件需要使用的判空函数，并清除 OptionalBy 指定的一次性判空函数
+func (cc *compositeCond) takeEmptyPredicate() EmptyPredicate {
+	isEmpty := cc.isEmpty
+	if cc.nextEmpty != nil {
+		isEmpty = cc.nextEmpty
+		cc.nextEmpty = nil
+	}
+	return isEmpty
 }
 
-func (cc *compositeCond) Add(matcher Matcher) Matcher {
-	cc.conds = append(cc.conds, matcher)
+func (cc *compositeCond) addCond(cond SQLCond) Matcher {
+	cc.nextEmpty = nil
+	cc.conds = append(cc.conds, cond)
 	return cc
 }
 
-func (cc *compositeCond) AddCond(cond SQLCond) Matcher {
+func (cc *compositeCond) addValueCond(value any, cond SQLCond) Matcher {
+	isEmpty := cc.takeEmptyPredicate()
+	if isEmpty != nil && isEmpty(value) {
+		return cc
+	}
 	cc.conds = append(cc.conds, cond)
 	return cc
 }
 
+func (cc *compositeCond) Add(matcher Matcher) Matcher {
+	return cc.addCond(matcher)
+}
+
+func (cc *compositeCond) AddCond(cond SQLCond) Matcher {
+	return cc.addCond(cond)
+}
+
 func (cc *compositeCond) Eq(column string, value any) Matcher {
-	cc.conds = append(cc.conds, newEqCond(column, value))
-	return cc
+	return cc.addValueCond(value, newEqCond(column, value))
 }
 func (cc *compositeCond) Ne(column string, value any) Matcher {
-	cc.conds = append(cc.conds, newNeCond(column, value))
-	return cc
+	return cc.addValueCond(value, newNeCond(column, value))
 }
 
 func (cc *compositeCond) Lt(column string, value any) Matcher {
-	cc.conds = append(cc.conds, newLtCond(column, value))
-	return cc
+	return cc.addValueCond(value, newLtCond(column, value))
 }
 func (cc *compositeCond) Lte(column string, value any) Matcher {
-	cc.conds = append(cc.conds, newLteCond(column, value))
-	return cc
+	return cc.addValueCond(value, newLteCond(column, value))
 }
 
 func (cc *compositeCond) Gt(column string, value any) Matcher {
-	cc.conds = append(cc.conds, newGtCond(column, value))
-	return cc
+	return cc.addValueCond(value, newGtCond(column, value))
 }
 func (cc *compositeCond) Gte(column string, value any) Matcher {
-	cc.conds = append(cc.conds, newGteCond(column, value))
-	return cc
+	return cc.addValueCond(value, newGteCond(column, value))
 }
 func (cc *compositeCond) In(column string, values []any) Matcher {
-	cc.conds = append(cc.conds, newInCond(column, values))
-	return cc
+	return cc.addValueCond(values, newInCond(column, values))
 }
 func (cc *compositeCond) NotIn(column string, values []any) Matcher {
-	cc.conds = append(cc.conds, newNotInCond(column, values))
-	return cc
+	return cc.addValueCond(values, newNotInCond(column, values))
+}
+
+func (cc *compositeCond) TupleIn(columns []string, rows [][]any) Matcher {
+	return cc.addValueCond(rows, newTupleInCond(columns, rows))
+}
+func (cc *compositeCond) TupleNotIn(columns []string, rows [][]any) Matcher {
+	return cc.addValueCond(rows, newTupleNotInCond(columns, rows))
 }
 
 func (cc *compositeCond) Like(column string, value string, likeStyle int) Matcher {
-	cc.conds = append(cc.conds, newLikeCond(column, value, likeStyle))
-	return cc
+	return cc.addValueCond(value, newLikeCond(column, value, likeStyle))
+}
+
+func (cc *compositeCond) LikeEscape(column string, value string, likeStyle int) Matcher {
+	return cc.addValueCond(value, newLikeEscapeCond(column, value, likeStyle))
+}
+
+func (cc *compositeCond) StartsWith(column string, value string) Matcher {
+	return cc.LikeEscape(column, value, LikeStyleRight)
+}
+
+func (cc *compositeCond) EndsWith(column string, value string) Matcher {
+	return cc.LikeEscape(column, value, LikeStyleLeft)
+}
+
+func (cc *compositeCond) Contains(column string, value string) Matcher {
+	return cc.LikeEscape(column, value, LikeStyleAll)
+}
+
+func (cc *compositeCond) Regexp(column string, pattern string) Matcher {
+	return cc.addValueCond(pattern, newRegexpCond(column, pattern))
+}
+
+func (cc *compositeCond) EqIgnoreCase(column string, value any) Matcher {
+	return cc.addValueCond(value, newCollateEqCond(column, value, IgnoreCaseCollation))
+}
+
+func (cc *compositeCond) EqCollate(column string, value any, collation string) Matcher {
+	return cc.addValueCond(value, newCollateEqCond(column, value, collation))
 }
 
 func (cc *compositeCond) Null(column string, not bool) Matcher {
-	cc.conds = append(cc.conds, newNullCond(column, not))
-	return cc
+	return cc.addCond(newNullCond(column, not))
 }
 
 func (cc *compositeCond) Between(column string, start any, end any) Matcher {
+	isEmpty := cc.takeEmptyPredicate()
+	if isEmpty != nil {
+		if isEmpty(start) {
+			start = nil
+		}
+		if isEmpty(end) {
+			end = nil
+		}
+		if start == nil && end == nil {
+			return cc
+		}
+	}
 	cc.conds = append(cc.conds, newBetweenCond(column, start, end))
 	return cc
 }
 
 func (cc *compositeCond) AddScalar(cond string) Matcher {
-	cc.conds = append(cc.conds, newScalarCond(cond))
-	return cc
+	return cc.addCond(newScalarCond(cond))
 }
 
 func (cc *compositeCond) BitAnd(column string, mask, target any) Matcher {
-	cc.conds = append(cc.conds, newBitwiseAndCond(column, mask, target))
-	return cc
+	return cc.addCond(newBitwiseAndCond(column, mask, target))
 }
 
 func (cc *compositeCond) BitwiseAnd(column string, mask, target any) Matcher {
-	cc.conds = append(cc.conds, newBitwiseAndCond(column, mask, target))
+	return cc.addCond(newBitwiseAndCond(column, mask, target))
+}
+
+func (cc *compositeCond) OptionalBy(isEmpty EmptyPredicate) Matcher {
+	cc.nextEmpty = isEmpty
 	return cc
 }
 
+func (cc *compositeCond) referColumns(columns []string) []string {
+	for _, cond := range cc.conds {
+		if referrer, ok := cond.(columnsReferrer); ok {
+			columns = referrer.referColumns(columns)
+		}
+	}
+	return columns
+}
+
 func (cc *compositeCond) ToSQL(args []any) (string, []any, error) {
 	var condSegs []string
 
@@ -216,6 +360,10 @@ type simpleCond struct {
 	value  any
 }
 
+func (sc *simpleCond) referColumns(columns []string) []string {
+	return append(columns, sc.column)
+}
+
 func (sc *simpleCond) ToSQL(args []any) (string, []any, error) {
 	return sc.column + " " + sc.op + " ?", append(args, sc.value), nil
 }
@@ -226,25 +374,82 @@ type inCond struct {
 	not    bool
 }
 
+func (ic *inCond) referColumns(columns []string) []string {
+	return append(columns, ic.column)
+}
+
 func (ic *inCond) ToSQL(args []any) (string, []any, error) {
 	if len(ic.values) == 0 {
 		return "", args, errors.New(ic.column + ": no param values")
 	}
-	holders := make([]string, len(ic.values))
-	for i := 0; i < len(ic.values); i++ {
-		holders[i] = "?"
+	op := " in ("
+	logicOp := logicOpOr
+	if ic.not {
+		op = " not in ("
+		logicOp = logicOpAnd
 	}
 
-	var builder strings.Builder
-	builder.WriteString(ic.column)
-	if ic.not {
-		builder.WriteString(" not in (")
-	} else {
-		builder.WriteString(" in (")
+	chunks := splitInChunks(ic.values, MaxInSize)
+	segs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		segs[i] = ic.column + op + strings.Repeat("?,", len(chunk)-1) + "?)"
+		args = append(args, chunk...)
 	}
-	builder.WriteString(strings.Join(holders, ","))
-	builder.WriteString(")")
-	return builder.String(), append(args, ic.values...), nil
+	if len(segs) == 1 {
+		return segs[0], args, nil
+	}
+	return "(" + strings.Join(segs, " "+logicOp+" ") + ")", args, nil
+}
+
+type tupleInCond struct {
+	columns []string
+	rows    [][]any
+	not     bool
+}
+
+func (tic *tupleInCond) referColumns(columns []string) []string {
+	return append(columns, tic.columns...)
+}
+
+func (tic *tupleInCond) ToSQL(args []any) (string, []any, error) {
+	colCount := len(tic.columns)
+	if colCount == 0 {
+		return "", args, errors.New("tuple in condition has no columns")
+	}
+	columnsStr := "(" + strings.Join(tic.columns, ",") + ")"
+	if len(tic.rows) == 0 {
+		return "", args, errors.New(columnsStr + ": no param values")
+	}
+	for i, row := range tic.rows {
+		if len(row) != colCount {
+			return "", args, fmt.Errorf("%s: row %d has %d values, but %d columns", columnsStr, i, len(row), colCount)
+		}
+	}
+	if len(args)+colCount*len(tic.rows) > maxPlaceholders {
+		return "", args, fmt.Errorf("%s: too many param values, placeholders exceed %d", columnsStr, maxPlaceholders)
+	}
+
+	rowHolder := "(" + strings.Repeat("?,", colCount-1) + "?)"
+	op := " in ("
+	logicOp := logicOpOr
+	if tic.not {
+		op = " not in ("
+		logicOp = logicOpAnd
+	}
+
+	var segs []string
+	for _, chunk := range splitInChunks(tic.rows, MaxInSize) {
+		holders := make([]string, len(chunk))
+		for i, row := range chunk {
+			holders[i] = rowHolder
+			args = append(args, row...)
+		}
+		segs = append(segs, columnsStr+op+strings.Join(holders, ",")+")")
+	}
+	if len(segs) == 1 {
+		return segs[0], args, nil
+	}
+	return "(" + strings.Join(segs, " "+logicOp+" ") + ")", args, nil
 }
 
 type betweenCond struct {
@@ -253,6 +458,10 @@ type betweenCond struct {
 	end    any
 }
 
+func (btc *betweenCond) referColumns(columns []string) []string {
+	return append(columns, btc.column)
+}
+
 func (btc *betweenCond) ToSQL(args []any) (string, []any, error) {
 	if btc.start == nil && btc.end == nil {
 		return "", args, errors.New(btc.column + " between condition is empty")
@@ -273,6 +482,10 @@ type nullCond struct {
 	not    bool
 }
 
+func (nc *nullCond) referColumns(columns []string) []string {
+	return append(columns, nc.column)
+}
+
 func (nc *nullCond) ToSQL(args []any) (string, []any, error) {
 	if nc.not {
 		return nc.column + " is not null", args, nil
@@ -285,6 +498,11 @@ type likeCond struct {
 	column    string
 	value     string
 	likeStyle int
+	escape    bool
+}
+
+func (likec *likeCond) referColumns(columns []string) []string {
+	return append(columns, likec.column)
 }
 
 func (likec *likeCond) ToSQL(args []any) (string, []any, error) {
@@ -292,6 +510,9 @@ func (likec *likeCond) ToSQL(args []any) (string, []any, error) {
 		return "", args, errors.New(likec.column + " like param is empty")
 	}
 	v := likec.value
+	if likec.escape {
+		v = escapeLikeValue(v)
+	}
 	switch likec.likeStyle {
 	case LikeStyleLeft:
 		v = "%" + v
@@ -303,9 +524,76 @@ func (likec *likeCond) ToSQL(args []any) (string, []any, error) {
 		return "", args, nil
 	}
 
+	if likec.escape {
+		return likec.column + " like ? escape '" + string(likeEscapeChar) + "'", append(args, v), nil
+	}
 	return likec.column + " like ?", append(args, v), nil
 }
 
+// escapeLikeValue 转义 like 值中的通配符 %、_ 以及转义字符 likeEscapeChar 本身
+func escapeLikeValue(v string) string {
+	if !strings.ContainsAny(v, "%_"+string(likeEscapeChar)) {
+		return v
+	}
+	var builder strings.Builder
+	builder.Grow(len(v) + 4)
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c == '%' || c == '_' || c == likeEscapeChar {
+			builder.WriteByte(likeEscapeChar)
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
+
+type regexpCond struct {
+	column  string
+	pattern string
+}
+
+func (rc *regexpCond) referColumns(columns []string) []string {
+	return append(columns, rc.column)
+}
+
+func (rc *regexpCond) ToSQL(args []any) (string, []any, error) {
+	if rc.pattern == "" {
+		return "", args, errors.New(rc.column + " regexp pattern is empty")
+	}
+	return rc.column + " regexp ?", append(args, rc.pattern), nil
+}
+
+type collateEqCond struct {
+	column    string
+	value     any
+	collation string
+}
+
+func (cec *collateEqCond) referColumns(columns []string) []string {
+	return append(columns, cec.column)
+}
+
+func (cec *collateEqCond) ToSQL(args []any) (string, []any, error) {
+	// collation 会被直接拼接到sql中，只允许字母、数字和下划线
+	if !isSimpleIdentifier(cec.collation) {
+		return "", args, errors.New(cec.column + ": invalid collation " + cec.collation)
+	}
+	return cec.column + " = ? collate " + cec.collation, append(args, cec.value), nil
+}
+
+func isSimpleIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
 type scalarCond struct {
 	cond string
 }
@@ -323,6 +611,10 @@ type bitwiseAndCond struct {
 	target any
 }
 
+func (bitAnd *bitwiseAndCond) referColumns(columns []string) []string {
+	return append(columns, bitAnd.column)
+}
+
 func (bitAnd *bitwiseAndCond) ToSQL(args []any) (string, []any, error) {
 	var ok bool
 	switch bitAnd.target.(type) {
@@ -0,0 +1,81 @@
+package daog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTupleIn(t *testing.T) {
+	sql, args, err := NewMatcher().TupleIn([]string{"a", "b"}, [][]any{{1, "x"}, {2, "y"}}).ToSQL(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "(a,b) in ((?,?),(?,?))" || len(args) != 4 {
+		t.Error(sql, args)
+	}
+
+	sql, _, err = NewMatcher().TupleNotIn([]string{"a", "b"}, [][]any{{1, "x"}}).ToSQL(nil)
+	if err != nil || sql != "(a,b) not in ((?,?))" {
+		t.Error(sql, err)
+	}
+
+	if _, _, err = NewMatcher().TupleIn([]string{"a", "b"}, [][]any{{1, "x"}, {2}}).ToSQL(nil); err == nil {
+		t.Error("arity mismatch should fail")
+	}
+
+	rows := make([][]any, maxPlaceholders/2+1)
+	for i := range rows {
+		rows[i] = []any{i, i}
+	}
+	if _, _, err = NewMatcher().TupleIn([]string{"a", "b"}, rows).ToSQL(nil); err == nil || !strings.Contains(err.Error(), "placeholders") {
+		t.Error("placeholder limit should fail", err)
+	}
+}
+
+func TestTupleInSplit(t *testing.T) {
+	rows := make([][]any, 1001)
+	for i := range rows {
+		rows[i] = []any{i, i}
+	}
+	sql, args, err := NewMatcher().TupleIn([]string{"a", "b"}, rows).ToSQL(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(sql, " in (") != 1 || strings.Contains(sql, " or ") || len(args) != 2*len(rows) {
+		t.Error("should not split without MaxInSize", sql[:40], len(args))
+	}
+
+	old := MaxInSize
+	defer func() {
+		MaxInSize = old
+	}()
+	MaxInSize = 500
+	sql, args, err = NewMatcher().TupleIn([]string{"a", "b"}, rows).ToSQL(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(sql, " in (") != 3 || !strings.Contains(sql, " or ") || len(args) != 2*len(rows) {
+		t.Error(sql[:40], len(args))
+	}
+}
+
+func TestEscapeLikeValue(t *testing.T) {
+	cases := map[string]string{
+		"abc":    "abc",
+		"50%":    "50!%",
+		"a_b":    "a!_b",
+		"wow!":   "wow!!",
+		`c:\dir`: `c:\dir`,
+		"!%_":    "!!!%!_",
+	}
+	for in, expected := range cases {
+		if got := escapeLikeValue(in); got != expected {
+			t.Errorf("%s: %s", in, got)
+		}
+	}
+
+	sql, args, err := NewMatcher().Contains("name", "50%").ToSQL(nil)
+	if err != nil || sql != "name like ? escape '!'" || args[0] != "%50!%%" {
+		t.Error(sql, args, err)
+	}
+}
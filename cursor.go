@@ -0,0 +1,309 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/rolandhe/daog/ttypes"
+	"strings"
+	"time"
+)
+
+// CursorSecret 游标签名使用的密钥，游标使用 HMAC-SHA256 签名，被篡改的游标会被拒绝。
+// 缺省为空，必须在应用启动时设置成自己的随机密钥，否则游标分页返回 ErrCursorSecretNotSet
+var CursorSecret []byte
+
+// ErrCursorSecretNotSet 没有设置 CursorSecret 时生成或者校验游标返回的错误
+var ErrCursorSecretNotSet = errors.New("daog.CursorSecret is not set")
+
+// ErrInvalidCursor 游标格式错误、签名不匹配或者与排序条件不一致时返回的错误
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorPager 游标(keyset)分页参数，与 Pager 的 limit offset,size 不同，它使用上一页最后一条数据的排序字段值作为条件，
+// 生成类似 where (a,b) > (?,?) order by a,b limit size 的sql，翻页的性能不会随着页码增加而下降，数据变化时也不会跳过或者重复数据。
+//
+// 排序字段最后总会加入主键(如果排序条件中没有，联合主键时加入所有缺少的主键字段)，以保证顺序唯一，排序字段不应该包含 NULL 值
+type CursorPager struct {
+	PageSize int
+	// Cursor 上一页查询返回的游标，空字符串表示查询第一页
+	Cursor string
+}
+
+// NewCursorPager 创建游标分页参数，cursor 为空字符串表示第一页
+func NewCursorPager(pageSize int, cursor string) *CursorPager {
+	return &CursorPager{pageSize, cursor}
+}
+
+type cursorToken struct {
+	Columns []string          `json:"c"`
+	Desc    []bool            `json:"d"`
+	Values  []json.RawMessage `json:"v"`
+}
+
+// QueryCursorPageListMatcher 根据查询条件 Matcher 及 CursorPager 返回一页数据，以及下一页的游标，没有下一页时游标为空字符串
+// orders 可变参数指定排序条件，游标的值与排序条件绑定，翻页时必须使用相同的排序条件
+func QueryCursorPageListMatcher[T any](tc *TransContext, m Matcher, meta *TableMeta[T], pager *CursorPager, orders ...*Order) ([]*T, string, error) {
+	return QueryCursorPageListMatcherWithViewObj(tc, m, meta, nil, pager, orders...)
+}
+
+// QueryCursorPageListMatcherWithViewColumns 与 QueryCursorPageListMatcher 类似，viewColumns 指定需要查询的表字段名，排序字段会被自动加入
+func QueryCursorPageListMatcherWithViewColumns[T any](tc *TransContext, m Matcher, meta *TableMeta[T], viewColumns []string, pager *CursorPager, orders ...*Order) ([]*T, string, error) {
+	view := &View{
+		viewColumns: viewColumns,
+		include:     true,
+	}
+	return QueryCursorPageListMatcherWithViewObj(tc, m, meta, view, pager, orders...)
+}
+
+// QueryCursorPageListMatcherWithViewObj 与 QueryCursorPageListMatcher 类似， view 指定需要查询的视图，排序字段会被自动加入视图
+func QueryCursorPageListMatcherWithViewObj[T any](tc *TransContext, m Matcher, meta *TableMeta[T], view *View, pager *CursorPager, orders ...*Order) ([]*T, string, error) {
+	return cursorPageCore(m, meta, view, pager, orders, func(cm Matcher, cv *View, p *Pager, os []*Order) ([]*T, error) {
+		return QueryPageListMatcherWithViewObj(tc, cm, meta, cv, p, os...)
+	})
+}
+
+// QueryCursorPageListMatcherForUpdate 与 QueryCursorPageListMatcherWithViewColumns 类似， 只是支持 for update
+// skipLocked, true 需要 SKIP LOCKED
+func QueryCursorPageListMatcherForUpdate[T any](tc *TransContext, m Matcher, meta *TableMeta[T], viewColumns []string, pager *CursorPager, skipLocked bool, orders ...*Order) ([]*T, string, error) {
+	view := &View{
+		viewColumns: viewColumns,
+		include:     true,
+	}
+	return cursorPageCore(m, meta, view, pager, orders, func(cm Matcher, cv *View, p *Pager, os []*Order) ([]*T, error) {
+		return QueryPageListMatcherWithViewObjForUpdate(tc, cm, meta, cv, p, skipLocked, os...)
+	})
+}
+
+type cursorPageQuery[T any] func(m Matcher, view *View, pager *Pager, orders []*Order) ([]*T, error)
+
+func cursorPageCore[T any](m Matcher, meta *TableMeta[T], view *View, pager *CursorPager, orders []*Order, query cursorPageQuery[T]) ([]*T, string, error) {
+	if pager == nil || pager.PageSize <= 0 {
+		return nil, "", invalidBatchSizeError
+	}
+	if len(CursorSecret) == 0 {
+		return nil, "", ErrCursorSecretNotSet
+	}
+	orders = cursorOrders(meta, orders)
+	columns := make([]string, len(orders))
+	desc := make([]bool, len(orders))
+	for i, order := range orders {
+		columns[i] = order.ColumnName
+		desc[i] = order.Desc
+	}
+
+	cm := m
+	if pager.Cursor != "" {
+		values, err := decodeCursor(meta, pager.Cursor, columns, desc)
+		if err != nil {
+			return nil, "", err
+		}
+		cm = NewMatcher()
+		if m != nil {
+			cm.Add(m)
+		}
+		cm.AddCond(&keysetCond{columns, desc, values})
+	}
+
+	list, err := query(cm, ensureViewColumns(view, columns), &Pager{pager.PageSize + 1, 1}, orders)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(list) <= pager.PageSize {
+		return list, "", nil
+	}
+	list = list[:pager.PageSize]
+	next, err := encodeCursor(meta, list[len(list)-1], columns, desc)
+	if err != nil {
+		return nil, "", err
+	}
+	return list, next, nil
+}
+
+// cursorOrders 复制排序条件，并在最后按照升序加入其中没有的主键字段，保证顺序唯一
+func cursorOrders[T any](meta *TableMeta[T], orders []*Order) []*Order {
+	keyColumns := meta.keyColumns()
+	ret := make([]*Order, 0, len(orders)+len(keyColumns))
+	ordered := map[string]bool{}
+	for _, order := range orders {
+		ordered[order.ColumnName] = true
+		ret = append(ret, order)
+	}
+	for _, column := range keyColumns {
+		if !ordered[column] {
+			ret = append(ret, NewOrder(column))
+		}
+	}
+	return ret
+}
+
+// ensureViewColumns 返回一个包含 columns 的新视图，原视图不变
+func ensureViewColumns(view *View, columns []string) *View {
+	if view == nil || len(view.viewColumns) == 0 {
+		return view
+	}
+	needs := map[string]bool{}
+	for _, c := range columns {
+		needs[c] = true
+	}
+	ret := &View{include: view.include, Distinct: view.Distinct, Hints: view.Hints}
+	for _, c := range view.viewColumns {
+		if view.include {
+			delete(needs, c)
+			ret.viewColumns = append(ret.viewColumns, c)
+		} else if !needs[c] {
+			ret.viewColumns = append(ret.viewColumns, c)
+		}
+	}
+	if view.include {
+		for _, c := range columns {
+			if needs[c] {
+				ret.viewColumns = append(ret.viewColumns, c)
+				delete(needs, c)
+			}
+		}
+	}
+	return ret
+}
+
+func encodeCursor[T any](meta *TableMeta[T], last *T, columns []string, desc []bool) (string, error) {
+	if len(CursorSecret) == 0 {
+		return "", ErrCursorSecretNotSet
+	}
+	token := &cursorToken{Columns: columns, Desc: desc}
+	for _, c := range columns {
+		raw, err := json.Marshal(cursorValue(meta.LookupFieldFunc(c, last, false)))
+		if err != nil {
+			return "", err
+		}
+		token.Values = append(token.Values, raw)
+	}
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signCursor(payload)), nil
+}
+
+// decodeCursor 校验游标签名以及排序条件，并把游标中的值转换成排序字段在 T 中对应的类型
+func decodeCursor[T any](meta *TableMeta[T], cursor string, columns []string, desc []bool) ([]any, error) {
+	if len(CursorSecret) == 0 {
+		return nil, ErrCursorSecretNotSet
+	}
+	encodedPayload, encodedSign, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sign, err := base64.RawURLEncoding.DecodeString(encodedSign)
+	if err != nil || !hmac.Equal(sign, signCursor(payload)) {
+		return nil, ErrInvalidCursor
+	}
+	token := &cursorToken{}
+	if err = json.Unmarshal(payload, token); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if len(token.Columns) != len(columns) || len(token.Desc) != len(columns) || len(token.Values) != len(columns) {
+		return nil, ErrInvalidCursor
+	}
+	ins := new(T)
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		if token.Columns[i] != c || token.Desc[i] != desc[i] {
+			return nil, ErrInvalidCursor
+		}
+		fieldPoint := meta.LookupFieldFunc(c, ins, true)
+		if fieldPoint == nil || bytes.Equal(token.Values[i], []byte("null")) {
+			return nil, ErrInvalidCursor
+		}
+		if err = unmarshalCursorValue(token.Values[i], fieldPoint); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = meta.LookupFieldFunc(c, ins, false)
+	}
+	return values, nil
+}
+
+// cursorValue ttypes.NormalDatetime 的 json 格式只精确到秒，DATETIME(3) 等字段会重复返回同一秒内的数据，
+// 游标中按 time.Time 的 RFC3339Nano 格式保存完整精度
+func cursorValue(value any) any {
+	if ndt, ok := value.(ttypes.NormalDatetime); ok {
+		return time.Time(ndt)
+	}
+	return value
+}
+
+// unmarshalCursorValue 是 cursorValue 的逆过程
+func unmarshalCursorValue(raw []byte, fieldPoint any) error {
+	if ndt, ok := fieldPoint.(*ttypes.NormalDatetime); ok {
+		return json.Unmarshal(raw, ndt.ToTimePointer())
+	}
+	return json.Unmarshal(raw, fieldPoint)
+}
+
+func signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, CursorSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// keysetCond 游标分页的条件，所有排序字段方向相同时生成 (a,b) > (?,?)，方向不同时生成 (a > ? or (a = ? and b < ?))
+type keysetCond struct {
+	columns []string
+	desc    []bool
+	values  []any
+}
+
+func (kc *keysetCond) referColumns(columns []string) []string {
+	return append(columns, kc.columns...)
+}
+
+func (kc *keysetCond) ToSQL(args []any) (string, []any, error) {
+	sameDirection := true
+	for _, d := range kc.desc {
+		if d != kc.desc[0] {
+			sameDirection = false
+			break
+		}
+	}
+	if sameDirection {
+		op := " > "
+		if kc.desc[0] {
+			op = " < "
+		}
+		if len(kc.columns) == 1 {
+			return kc.columns[0] + op + "?", append(args, kc.values[0]), nil
+		}
+		holders := "(" + strings.Repeat("?,", len(kc.columns)-1) + "?)"
+		return "(" + strings.Join(kc.columns, ",") + ")" + op + holders, append(args, kc.values...), nil
+	}
+
+	var segs []string
+	for i, c := range kc.columns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, kc.columns[j]+" = ?")
+			args = append(args, kc.values[j])
+		}
+		if kc.desc[i] {
+			parts = append(parts, c+" < ?")
+		} else {
+			parts = append(parts, c+" > ?")
+		}
+		args = append(args, kc.values[i])
+		if len(parts) == 1 {
+			segs = append(segs, parts[0])
+		} else {
+			segs = append(segs, "("+strings.Join(parts, " and ")+")")
+		}
+	}
+	return "(" + strings.Join(segs, " or ") + ")", args, nil
+}
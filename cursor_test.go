@@ -0,0 +1,118 @@
+package daog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rolandhe/daog/ttypes"
+)
+
+func TestKeysetCond(t *testing.T) {
+	kc := &keysetCond{[]string{"status", "id"}, []bool{false, false}, []any{int32(1), int64(10)}}
+	condi, args, err := kc.ToSQL(nil)
+	if err != nil || condi != "(status,id) > (?,?)" || !reflect.DeepEqual(args, []any{int32(1), int64(10)}) {
+		t.Error(condi, args, err)
+	}
+
+	kc = &keysetCond{[]string{"id"}, []bool{true}, []any{int64(10)}}
+	condi, args, err = kc.ToSQL(nil)
+	if err != nil || condi != "id < ?" || !reflect.DeepEqual(args, []any{int64(10)}) {
+		t.Error(condi, args, err)
+	}
+
+	kc = &keysetCond{[]string{"status", "name", "id"}, []bool{true, false, false}, []any{int32(1), "a", int64(10)}}
+	condi, args, err = kc.ToSQL(nil)
+	expected := "(status < ? or (status = ? and name > ?) or (status = ? and name = ? and id > ?))"
+	if err != nil || condi != expected {
+		t.Error(condi, err)
+	}
+	if !reflect.DeepEqual(args, []any{int32(1), int32(1), "a", int32(1), "a", int64(10)}) {
+		t.Error(args)
+	}
+}
+
+func TestCursorToken(t *testing.T) {
+	old := CursorSecret
+	defer func() {
+		CursorSecret = old
+	}()
+	columns := []string{"name", "id"}
+	desc := []bool{true, false}
+	last := &filterSample{Id: 10, Name: "tom"}
+
+	CursorSecret = nil
+	if _, err := encodeCursor(filterSampleMeta, last, columns, desc); !errors.Is(err, ErrCursorSecretNotSet) {
+		t.Error(err)
+	}
+
+	CursorSecret = []byte("test-secret")
+	cursor, err := encodeCursor(filterSampleMeta, last, columns, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := decodeCursor(filterSampleMeta, cursor, columns, desc)
+	if err != nil || !reflect.DeepEqual(values, []any{"tom", int64(10)}) {
+		t.Error(values, err)
+	}
+
+	if _, err = decodeCursor(filterSampleMeta, cursor, columns, []bool{false, false}); err != ErrInvalidCursor {
+		t.Error("orders changed", err)
+	}
+
+	payload, sign, _ := strings.Cut(cursor, ".")
+	tampered := []byte(payload)
+	tampered[len(tampered)/2] ^= 1
+	if _, err = decodeCursor(filterSampleMeta, string(tampered)+"."+sign, columns, desc); err != ErrInvalidCursor {
+		t.Error("tampered payload", err)
+	}
+
+	CursorSecret = []byte("another-secret")
+	if _, err = decodeCursor(filterSampleMeta, cursor, columns, desc); err != ErrInvalidCursor {
+		t.Error("secret changed", err)
+	}
+}
+
+func TestCursorSecretCheckedAtEntry(t *testing.T) {
+	old := CursorSecret
+	defer func() {
+		CursorSecret = old
+	}()
+	CursorSecret = nil
+	db := &fakeDB{}
+	tc := newFakeTc(t, db)
+	_, _, err := QueryCursorPageListMatcher(tc, nil, filterSampleMeta, NewCursorPager(10, ""))
+	if !errors.Is(err, ErrCursorSecretNotSet) {
+		t.Error(err)
+	}
+	if len(db.queries) != 0 {
+		t.Error("query executed without secret", db.queries)
+	}
+}
+
+func TestCursorDatetimePrecision(t *testing.T) {
+	old := CursorSecret
+	defer func() {
+		CursorSecret = old
+	}()
+	CursorSecret = []byte("test-secret")
+	columns := []string{"create_at", "id"}
+	desc := []bool{false, false}
+	createAt := time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.Local)
+	last := &filterSample{Id: 10, CreateAt: ttypes.NormalDatetime(createAt)}
+
+	cursor, err := encodeCursor(filterSampleMeta, last, columns, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := decodeCursor(filterSampleMeta, cursor, columns, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := values[0].(ttypes.NormalDatetime)
+	if !ok || !time.Time(got).Equal(createAt) {
+		t.Error(values[0])
+	}
+}
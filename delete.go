@@ -13,12 +13,7 @@ import (
 //
 // 返回值: 删除记录数，是否出错
 func DeleteById[T any](tc *TransContext, id int64, meta *TableMeta[T]) (int64, error) {
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
-	}
-	m.Eq(fieldId, id)
+	m := NewMatcher().Eq(meta.idColumn(), id)
 	return DeleteByMatcher(tc, m, meta)
 }
 
@@ -27,17 +22,15 @@ func DeleteById[T any](tc *TransContext, id int64, meta *TableMeta[T]) (int64, e
 // 参数: ids 一批主键 , meta 表的元数据，由compile编译生成，比如  GroupInfo.GroupInfoMeta
 //
 // 返回值: 删除记录数及是否出错
+//
+// 主键个数超过 MaxInSize 时，会在同一个 tc 内分批执行多条 delete 语句，返回值是各批删除记录数之和，某一批失败时的返回值见 MaxInSize
 func DeleteByIds[T any](tc *TransContext, ids []int64, meta *TableMeta[T]) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
-	}
-	m.In(fieldId, ConvertToAnySlice(ids))
-	return DeleteByMatcher(tc, m, meta)
+	return execInChunks(tc, ids, MaxInSize, func(chunk []int64) (int64, error) {
+		return DeleteByMatcher(tc, NewMatcher().In(meta.idColumn(), ConvertToAnySlice(chunk)), meta)
+	})
 }
 
 // DeleteByMatcher 通过匹配条件删除数据，返回删除记录数及是否出错
@@ -47,6 +40,9 @@ func DeleteByMatcher[T any](tc *TransContext, matcher Matcher, meta *TableMeta[T
 		GLogger.Info(tc.ctx, "delete must has condition")
 		return 0, nil
 	}
+	if err := checkMatcherColumns(meta, matcher); err != nil {
+		return 0, err
+	}
 	var args []any
 	condi, args, err := matcher.ToSQL(args)
 	if err != nil {
@@ -59,5 +55,10 @@ func DeleteByMatcher[T any](tc *TransContext, matcher Matcher, meta *TableMeta[T
 
 	sql := base + " where " + condi
 
-	return execSQLCore(tc, sql, args)
+	affect, err := execSQLCore(tc, sql, args)
+	if err != nil {
+		return 0, err
+	}
+	markTableModified(tc, meta)
+	return affect, nil
 }
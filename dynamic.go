@@ -0,0 +1,148 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+)
+
+// ResultTable QueryTable 返回的列式结果，Columns 是字段名，Types 是字段的数据库类型名(比如 VARCHAR、DECIMAL、DATETIME)，
+// Rows 中每行数据的顺序与 Columns 相同
+type ResultTable struct {
+	Columns []string
+	Types   []string
+	Rows    [][]any
+}
+
+// QueryMaps 执行任意select sql语句，每行数据以 map 返回，key 是字段名，适合管理工具、报表导出等结果结构在编译期未知的场景。
+// 字段值按照数据库类型转换:
+//
+//	字符类型转换成 string，二进制类型(BLOB/BINARY/VARBINARY/BIT)保留 []byte
+//	整数类型转换成 int64，无符号整数转换成 uint64，FLOAT/DOUBLE 转换成 float64
+//	DECIMAL 转换成 decimal.Decimal
+//	DATETIME/TIMESTAMP 转换成 ttypes.NormalDatetime，DATE 转换成 ttypes.NormalDate
+//	NULL 转换成 nil
+func QueryMaps(tc *TransContext, sql string, args ...any) ([]map[string]any, error) {
+	table, err := QueryTable(tc, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return table.toMaps(), nil
+}
+
+// QueryTable 与 QueryMaps 类似，但返回列式的结果，同时包含字段的数据库类型，字段值的转换规则与 QueryMaps 相同
+func QueryTable(tc *TransContext, sqlText string, args ...any) (*ResultTable, error) {
+	var table *ResultTable
+	err := queryRowsCore(tc, sqlText, args, func(rows *sql.Rows) error {
+		var err error
+		table, err = scanResultTable(rows, convertDynamicValue)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (table *ResultTable) toMaps() []map[string]any {
+	ret := make([]map[string]any, 0, len(table.Rows))
+	for _, values := range table.Rows {
+		row := make(map[string]any, len(table.Columns))
+		for i, column := range table.Columns {
+			row[column] = values[i]
+		}
+		ret = append(ret, row)
+	}
+	return ret
+}
+
+func scanResultTable(rows *sql.Rows, convert func(typeName string, value any) (any, error)) (*ResultTable, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	table := &ResultTable{
+		Columns: make([]string, len(columnTypes)),
+		Types:   make([]string, len(columnTypes)),
+	}
+	for i, ct := range columnTypes {
+		table.Columns[i] = ct.Name()
+		table.Types[i] = ct.DatabaseTypeName()
+	}
+	for rows.Next() {
+		values := make([]any, len(columnTypes))
+		scanFields := make([]any, len(columnTypes))
+		for i := range values {
+			scanFields[i] = &values[i]
+		}
+		if err = rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+		for i := range values {
+			if values[i], err = convert(table.Types[i], values[i]); err != nil {
+				return nil, err
+			}
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table, nil
+}
+
+// convertDynamicValue 根据数据库类型名转换 driver 返回的值，driver 使用文本协议时大部分值是 []byte
+func convertDynamicValue(typeName string, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch v := value.(type) {
+	case time.Time:
+		if typeName == "DATE" {
+			return ttypes.NormalDate(v), nil
+		}
+		return ttypes.NormalDatetime(v), nil
+	case []byte:
+		return convertDynamicBytes(typeName, v)
+	}
+	return value, nil
+}
+
+func convertDynamicBytes(typeName string, b []byte) (any, error) {
+	s := string(b)
+	switch {
+	case typeName == "DECIMAL":
+		return decimal.NewFromString(s)
+	case typeName == "DATETIME" || typeName == "TIMESTAMP":
+		if strings.HasPrefix(s, "0000-00-00") {
+			return ttypes.NormalDatetime{}, nil
+		}
+		ndt, err := ttypes.ParseNormalDatetime(s)
+		if err != nil {
+			return nil, err
+		}
+		return *ndt, nil
+	case typeName == "DATE":
+		if strings.HasPrefix(s, "0000-00-00") {
+			return ttypes.NormalDate{}, nil
+		}
+		nd, err := ttypes.ParseNormalDate(s)
+		if err != nil {
+			return nil, err
+		}
+		return *nd, nil
+	case strings.HasPrefix(typeName, "UNSIGNED "):
+		return strconv.ParseUint(s, 10, 64)
+	case strings.HasSuffix(typeName, "INT") || typeName == "YEAR":
+		return strconv.ParseInt(s, 10, 64)
+	case typeName == "FLOAT" || typeName == "DOUBLE":
+		return strconv.ParseFloat(s, 64)
+	case strings.HasSuffix(typeName, "BLOB") || strings.HasSuffix(typeName, "BINARY") || typeName == "BIT" || typeName == "GEOMETRY":
+		return b, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,39 @@
+package daog
+
+import (
+	"testing"
+
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+)
+
+func TestConvertDynamicValue(t *testing.T) {
+	v, err := convertDynamicValue("DECIMAL", []byte("12.50"))
+	if d, ok := v.(decimal.Decimal); err != nil || !ok || d.String() != "12.5" {
+		t.Error(v, err)
+	}
+	v, err = convertDynamicValue("DATETIME", []byte("2024-01-02 03:04:05"))
+	if d, ok := v.(ttypes.NormalDatetime); err != nil || !ok || d.String() != "2024-01-02 03:04:05" {
+		t.Error(v, err)
+	}
+	v, err = convertDynamicValue("UNSIGNED BIGINT", []byte("18446744073709551615"))
+	if u, ok := v.(uint64); err != nil || !ok || u != 18446744073709551615 {
+		t.Error(v, err)
+	}
+	v, err = convertDynamicValue("VARCHAR", []byte("abc"))
+	if v != "abc" || err != nil {
+		t.Error(v, err)
+	}
+	v, _ = convertDynamicValue("BLOB", []byte("abc"))
+	if _, ok := v.([]byte); !ok {
+		t.Error(v)
+	}
+	if v, _ = convertDynamicValue("INT", nil); v != nil {
+		t.Error(v)
+	}
+
+	v, _ = bytesAsStringValue("DECIMAL", []byte("12.50"))
+	if v != "12.50" {
+		t.Error(v)
+	}
+}
@@ -1,5 +1,20 @@
 package dal
 
+import (
+	"github.com/rolandhe/daog"
+)
+
+// BitsSampleCols 手写的带类型字段描述，compilex 不会生成它，表结构变化时需要同步修改
+var BitsSampleCols = struct {
+	Id     daog.Column[BitsSample, int64]
+	V      daog.Column[BitsSample, int32]
+	Status daog.Column[BitsSample, int32]
+}{
+	daog.NewColumn[BitsSample, int64]("id"),
+	daog.NewColumn[BitsSample, int32]("v"),
+	daog.NewColumn[BitsSample, int32]("status"),
+}
+
 func init() {
 	// you should do external working, e.g, setup BitsSampleMeta.ShardingFunc
 }
@@ -1,5 +1,30 @@
 package dal
 
+import (
+	"github.com/rolandhe/daog"
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+)
+
+// GroupInfoCols 手写的带类型字段描述，compilex 不会生成它，表结构变化时需要同步修改
+var GroupInfoCols = struct {
+	Id          daog.Column[GroupInfo, int64]
+	Name        daog.Column[GroupInfo, string]
+	MainData    daog.Column[GroupInfo, string]
+	Content     daog.Column[GroupInfo, string]
+	BinData     daog.Column[GroupInfo, []byte]
+	CreateAt    daog.Column[GroupInfo, ttypes.NormalDatetime]
+	TotalAmount daog.Column[GroupInfo, decimal.Decimal]
+}{
+	daog.NewColumn[GroupInfo, int64]("id"),
+	daog.NewColumn[GroupInfo, string]("name"),
+	daog.NewColumn[GroupInfo, string]("main_data"),
+	daog.NewColumn[GroupInfo, string]("content"),
+	daog.NewColumn[GroupInfo, []byte]("bin_data"),
+	daog.NewColumn[GroupInfo, ttypes.NormalDatetime]("create_at"),
+	daog.NewColumn[GroupInfo, decimal.Decimal]("total_amount"),
+}
+
 func init() {
 	// you should do external working, e.g, setup GroupInfoMeta.ShardingFunc
 }
@@ -1,5 +1,23 @@
 package dal
 
+import (
+	"github.com/rolandhe/daog"
+	"github.com/rolandhe/daog/ttypes"
+)
+
+// UserInfoCols 手写的带类型字段描述，compilex 不会生成它，表结构变化时需要同步修改
+var UserInfoCols = struct {
+	Id       daog.Column[UserInfo, int64]
+	Name     daog.Column[UserInfo, string]
+	CreateAt daog.Column[UserInfo, ttypes.NormalDatetime]
+	ModifyAt daog.Column[UserInfo, ttypes.NilableDatetime]
+}{
+	daog.NewColumn[UserInfo, int64]("id"),
+	daog.NewColumn[UserInfo, string]("name"),
+	daog.NewColumn[UserInfo, ttypes.NormalDatetime]("create_at"),
+	daog.NewColumn[UserInfo, ttypes.NilableDatetime]("modify_at"),
+}
+
 func init() {
 	// you should do external working, e.g, setup UserInfoMeta.ShardingFunc
 }
@@ -0,0 +1,182 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"crypto/md5"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rolandhe/daog/utils"
+)
+
+// ErrFullTableScan 开启 ExplainGuard 并且 ExplainGuardOptions.Reject 为 true 时，查询的执行计划中有超过限制的全表扫描时返回的错误
+var ErrFullTableScan = errors.New("full table scan")
+
+// ExplainGuard 开发模式下的执行计划检查，缺省为nil，表示不检查，不要在生产环境开启。
+// 开启后，每一种新的查询语句(以sql文本的md5区分，不包含参数)在第一次执行前都会先执行 EXPLAIN FORMAT=JSON，
+// 如果执行计划中有 access_type 为 ALL 并且预估扫描行数超过 MaxFullScanRows 的表，则输出日志或者拒绝执行
+var ExplainGuard *ExplainGuardOptions
+
+// ExplainGuardOptions 执行计划检查的选项
+type ExplainGuardOptions struct {
+	// MaxFullScanRows 允许全表扫描的最大预估行数
+	MaxFullScanRows int64
+	// Reject 为 true 时拒绝执行并返回 ErrFullTableScan，否则只输出日志
+	Reject bool
+
+	checked sync.Map
+}
+
+// ExplainResult EXPLAIN FORMAT=JSON 的解析结果，Raw 是原始的 json，Tables 是执行计划中涉及的所有表
+type ExplainResult struct {
+	Raw       string
+	QueryCost string
+	Tables    []*ExplainTable
+}
+
+// ExplainTable 执行计划中的一个表
+type ExplainTable struct {
+	TableName           string   `json:"table_name"`
+	AccessType          string   `json:"access_type"`
+	PossibleKeys        []string `json:"possible_keys"`
+	Key                 string   `json:"key"`
+	UsedKeyParts        []string `json:"used_key_parts"`
+	KeyLength           string   `json:"key_length"`
+	RowsExaminedPerScan int64    `json:"rows_examined_per_scan"`
+	RowsProducedPerJoin int64    `json:"rows_produced_per_join"`
+	Filtered            string   `json:"filtered"`
+	AttachedCondition   string   `json:"attached_condition"`
+}
+
+// FullScans 返回 access_type 为 ALL 并且预估扫描行数超过 maxRows 的表
+func (result *ExplainResult) FullScans(maxRows int64) []*ExplainTable {
+	var ret []*ExplainTable
+	for _, table := range result.Tables {
+		if table.AccessType == "ALL" && table.RowsExaminedPerScan > maxRows {
+			ret = append(ret, table)
+		}
+	}
+	return ret
+}
+
+// Explain 对根据 Matcher、Pager、orders 生成的查询语句执行 EXPLAIN FORMAT=JSON，并返回解析后的执行计划，参数与 QueryPageListMatcher 相同
+func Explain[T any](tc *TransContext, m Matcher, meta *TableMeta[T], pager *Pager, orders ...*Order) (*ExplainResult, error) {
+	sql, args, err := selectQuery(meta, tc.ctx, m, pager, orders, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ExplainRawSQL(tc, sql, args...)
+}
+
+// ExplainRawSQL 对原生sql执行 EXPLAIN FORMAT=JSON，并返回解析后的执行计划
+func ExplainRawSQL(tc *TransContext, sqlText string, args ...any) (*ExplainResult, error) {
+	var raw string
+	err := queryRowsCore(tc, "explain format=json "+sqlText, args, func(rows *sql.Rows) error {
+		if !rows.Next() {
+			return errors.New("explain returns no plan")
+		}
+		return rows.Scan(&raw)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseExplainJSON(raw)
+}
+
+func parseExplainJSON(raw string) (*ExplainResult, error) {
+	var plan struct {
+		QueryBlock json.RawMessage `json:"query_block"`
+	}
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return nil, err
+	}
+	result := &ExplainResult{Raw: raw}
+	var block struct {
+		CostInfo struct {
+			QueryCost string `json:"query_cost"`
+		} `json:"cost_info"`
+	}
+	if err := json.Unmarshal(plan.QueryBlock, &block); err != nil {
+		return nil, err
+	}
+	result.QueryCost = block.CostInfo.QueryCost
+	if err := collectExplainTables(plan.QueryBlock, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// collectExplainTables 递归查找执行计划中所有的 "table" 节点，它们可能嵌套在 nested_loop、ordering_operation 等节点中
+func collectExplainTables(node json.RawMessage, result *ExplainResult) error {
+	trimmed := strings.TrimSpace(string(node))
+	if trimmed == "" {
+		return nil
+	}
+	switch trimmed[0] {
+	case '{':
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(node, &obj); err != nil {
+			return err
+		}
+		for key, value := range obj {
+			if key == "table" {
+				table := &ExplainTable{}
+				if err := json.Unmarshal(value, table); err != nil {
+					return err
+				}
+				result.Tables = append(result.Tables, table)
+			}
+			if err := collectExplainTables(value, result); err != nil {
+				return err
+			}
+		}
+	case '[':
+		var arr []json.RawMessage
+		if err := json.Unmarshal(node, &arr); err != nil {
+			return err
+		}
+		for _, value := range arr {
+			if err := collectExplainTables(value, result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// guardQuery 在开启 ExplainGuard 时检查查询语句的执行计划，每种查询语句只检查一次，检查结果被记录下来
+func guardQuery(tc *TransContext, sqlText string, args []any) error {
+	guard := ExplainGuard
+	if guard == nil || len(sqlText) < 6 || !strings.EqualFold(sqlText[:6], "select") {
+		return nil
+	}
+	// shape 只包含sql文本，与 ExecSQLBefore 日志中包含参数的 sqlMd5 不同，日志中使用 sqlShape 以免混淆
+	sum := md5.Sum([]byte(sqlText))
+	shape := utils.ToUpperHexString(sum[:])
+	if verdict, ok := guard.checked.Load(shape); ok {
+		if verdict != nil {
+			return verdict.(error)
+		}
+		return nil
+	}
+	var verdict error
+	result, err := ExplainRawSQL(tc, sqlText, args...)
+	if err != nil {
+		GLogger.Error(tc.ctx, err)
+	} else if scans := result.FullScans(guard.MaxFullScanRows); len(scans) > 0 {
+		msg := fmt.Sprintf("full table scan on %s, estimated rows %d, sqlShape=%s, sql: %s", scans[0].TableName, scans[0].RowsExaminedPerScan, shape, sqlText)
+		if guard.Reject {
+			verdict = fmt.Errorf("%w: %s", ErrFullTableScan, msg)
+		} else {
+			GLogger.Info(tc.ctx, msg)
+		}
+	}
+	guard.checked.Store(shape, verdict)
+	return verdict
+}
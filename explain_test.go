@@ -0,0 +1,57 @@
+package daog
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestParseExplainJSON(t *testing.T) {
+	raw := `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "120.50"},
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {"table": {"table_name": "a", "access_type": "ALL", "rows_examined_per_scan": 5000, "possible_keys": ["idx_x"]}},
+        {"table": {"table_name": "b", "access_type": "ref", "key": "idx_a_id", "rows_examined_per_scan": 2, "used_key_parts": ["a_id"]}}
+      ]
+    },
+    "optimized_away_subqueries": [
+      {"query_block": {"select_id": 2, "table": {"table_name": "c", "access_type": "ALL", "rows_examined_per_scan": 10}}}
+    ]
+  }
+}`
+	result, err := parseExplainJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.QueryCost != "120.50" || result.Raw != raw {
+		t.Error(result.QueryCost)
+	}
+	var names []string
+	tables := map[string]*ExplainTable{}
+	for _, table := range result.Tables {
+		names = append(names, table.TableName)
+		tables[table.TableName] = table
+	}
+	sort.Strings(names)
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Fatal(names)
+	}
+	if tables["b"].Key != "idx_a_id" || tables["b"].UsedKeyParts[0] != "a_id" || tables["a"].PossibleKeys[0] != "idx_x" {
+		t.Error(tables["a"], tables["b"])
+	}
+
+	scans := result.FullScans(100)
+	if len(scans) != 1 || scans[0].TableName != "a" {
+		t.Error(scans)
+	}
+	if len(result.FullScans(1)) != 2 {
+		t.Error("both full scans should exceed 1 row")
+	}
+
+	if _, err = parseExplainJSON("not json"); err == nil {
+		t.Error("invalid json should fail")
+	}
+}
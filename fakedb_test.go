@@ -0,0 +1,153 @@
+package daog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	txrequest "github.com/rolandhe/daog/tx"
+)
+
+// fakeDB 测试使用的内存数据库驱动，记录执行的sql及参数，查询时返回预先设置的结果
+type fakeDB struct {
+	queries []string
+	args    [][]any
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+	// respond 不为 nil 时根据sql及参数返回查询结果，替代 columns 及 rows
+	respond func(query string, args []any) ([]string, [][]driver.Value)
+	// lastInsertId 执行 insert 等语句后返回的自增长id
+	lastInsertId int64
+	mu           sync.Mutex
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db}, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+func (db *fakeDB) lastQuery() string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.queries) == 0 {
+		return ""
+	}
+	return db.queries[len(db.queries)-1]
+}
+
+// newFakeTc 创建使用 fakeDB 的 TransContext，事务类型是 txrequest.RequestNone
+func newFakeTc(t *testing.T, db *fakeDB) *TransContext {
+	sqlDB := sql.OpenDB(db)
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		sqlDB.Close()
+	})
+	return &TransContext{txRequest: txrequest.RequestNone, conn: conn, status: tcStatusInit, ctx: context.Background()}
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := c.record(query, args)
+	if c.db.respond != nil {
+		columns, rows := c.db.respond(query, values)
+		return &fakeRows{columns: columns, rows: rows}, nil
+	}
+	return &fakeRows{columns: c.db.columns, types: c.db.types, rows: c.db.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query, args)
+	return fakeResult{c.db.lastInsertId}, nil
+}
+
+type fakeResult struct {
+	lastInsertId int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.lastInsertId, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+func (c *fakeConn) record(query string, args []driver.NamedValue) []any {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, query)
+	c.db.args = append(c.db.args, values)
+	return values
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	if index < len(r.types) {
+		return r.types[index]
+	}
+	return ""
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,450 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 过滤表达式支持的操作符
+const (
+	FilterOpEq      = "eq"
+	FilterOpNe      = "ne"
+	FilterOpLt      = "lt"
+	FilterOpLte     = "lte"
+	FilterOpGt      = "gt"
+	FilterOpGte     = "gte"
+	FilterOpIn      = "in"
+	FilterOpNotIn   = "nin"
+	FilterOpLike    = "like"
+	FilterOpBetween = "between"
+	FilterOpNull    = "null"
+	FilterOpNotNull = "notnull"
+)
+
+// 查询串格式中使用的参数名
+const (
+	FilterParamFilter   = "filter"
+	FilterParamSort     = "sort"
+	FilterParamPage     = "page"
+	FilterParamPageSize = "size"
+)
+
+const (
+	defaultFilterPageSize = 20
+	defaultFilterMaxSize  = 500
+)
+
+// FilterRequest 过滤请求的json描述, 比如:
+//
+//	{"filters":[{"column":"status","op":"in","values":[1,2]},{"column":"name","op":"like","values":["abc"]}],
+//	 "sorts":[{"column":"create_at","desc":true}],"pageNumber":1,"pageSize":20}
+type FilterRequest struct {
+	Filters    []*FilterItem `json:"filters"`
+	Sorts      []*FilterSort `json:"sorts"`
+	PageNumber int           `json:"pageNumber"`
+	PageSize   int           `json:"pageSize"`
+}
+
+// FilterItem 单个过滤条件，Values 中的值可以是 json 字符串，也可以是 json 数字或者布尔值，最终都会按照目标字段的类型进行转换
+type FilterItem struct {
+	Column string            `json:"column"`
+	Op     string            `json:"op"`
+	Values []json.RawMessage `json:"values"`
+}
+
+// FilterSort 单个排序条件
+type FilterSort struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
+}
+
+// FilterQuery 解析过滤请求的结果，可以直接用于 QueryPageListMatcher 等函数
+type FilterQuery struct {
+	Matcher Matcher
+	Orders  []*Order
+	Pager   *Pager
+}
+
+// FilterRule 针对一张表的过滤规则，描述了哪些字段可以被过滤、每个字段允许哪些操作符、哪些字段可以排序，以及分页大小的限制。
+// 只有白名单内的字段和操作符才会被接受，客户端传入的字段名不会被直接拼接进 sql
+type FilterRule[T any] struct {
+	meta            *TableMeta[T]
+	allowedOps      map[string]map[string]bool
+	sortable        map[string]bool
+	defaultPageSize int
+	maxPageSize     int
+	err             error
+}
+
+// NewFilterRule 创建一个空的过滤规则，需要通过 Allow 和 AllowSort 添加白名单
+func NewFilterRule[T any](meta *TableMeta[T]) *FilterRule[T] {
+	return &FilterRule[T]{
+		meta:            meta,
+		allowedOps:      map[string]map[string]bool{},
+		sortable:        map[string]bool{},
+		defaultPageSize: defaultFilterPageSize,
+		maxPageSize:     defaultFilterMaxSize,
+	}
+}
+
+// Allow 允许对 column 字段使用 ops 指定的操作符进行过滤，column 必须是表字段，否则解析时会返回错误
+func (rule *FilterRule[T]) Allow(column string, ops ...string) *FilterRule[T] {
+	if !rule.meta.HasColumn(column) {
+		rule.err = fmt.Errorf("%w: filter column %q is not a column of table %s", ErrUnknownColumn, column, rule.meta.Table)
+		return rule
+	}
+	allowed := rule.allowedOps[column]
+	if allowed == nil {
+		allowed = map[string]bool{}
+		rule.allowedOps[column] = allowed
+	}
+	for _, op := range ops {
+		allowed[op] = true
+	}
+	return rule
+}
+
+// AllowSort 允许按照 columns 指定的字段排序
+func (rule *FilterRule[T]) AllowSort(columns ...string) *FilterRule[T] {
+	for _, column := range columns {
+		if !rule.meta.HasColumn(column) {
+			rule.err = fmt.Errorf("%w: sort column %q is not a column of table %s", ErrUnknownColumn, column, rule.meta.Table)
+			return rule
+		}
+		rule.sortable[column] = true
+	}
+	return rule
+}
+
+// SetPageSize 设置缺省的分页大小和最大分页大小，请求的分页大小超过最大值时按最大值处理
+func (rule *FilterRule[T]) SetPageSize(defaultPageSize int, maxPageSize int) *FilterRule[T] {
+	rule.defaultPageSize = defaultPageSize
+	rule.maxPageSize = maxPageSize
+	return rule
+}
+
+// ParseJSON 解析json格式的过滤请求，格式参照 FilterRequest
+func (rule *FilterRule[T]) ParseJSON(data []byte) (*FilterQuery, error) {
+	req := &FilterRequest{}
+	if err := json.Unmarshal(data, req); err != nil {
+		return nil, err
+	}
+	return rule.Parse(req)
+}
+
+// ParseQueryString 解析查询串格式的过滤请求，比如：
+//
+//	filter=status:in:1,2;name:like:abc;create_at:between:2024-01-01,2024-02-01&sort=-create_at,id&page=1&size=20
+//
+// filter 由多个以 ; 分隔的 字段:操作符:值 组成，in、nin 和 between 的多个值以 , 分隔，between 的某一端可以为空，null 和 notnull 不需要值;
+// sort 由多个以 , 分隔的字段组成，字段前加 - 表示降序; page 是页码，从1开始; size 是分页大小，
+// 没有 page 和 size 时查询第1页，分页大小是缺省值，不会返回全部数据
+func (rule *FilterRule[T]) ParseQueryString(rawQuery string) (*FilterQuery, error) {
+	// url.ParseQuery 不接受未转义的 ; ，而过滤表达式使用 ; 分隔条件，所以这里只按照 & 拆分
+	values := url.Values{}
+	for _, kv := range strings.Split(rawQuery, "&") {
+		if kv == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(kv, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, err
+		}
+		values.Add(key, value)
+	}
+	return rule.ParseQuery(values)
+}
+
+// ParseQuery 与 ParseQueryString 相同，只是输入是已经解析好的 url.Values
+func (rule *FilterRule[T]) ParseQuery(values url.Values) (*FilterQuery, error) {
+	req := &FilterRequest{}
+	filterExpr := values.Get(FilterParamFilter)
+	for _, clause := range strings.Split(filterExpr, ";") {
+		if strings.TrimSpace(clause) == "" {
+			continue
+		}
+		item, err := parseFilterClause(clause)
+		if err != nil {
+			return nil, err
+		}
+		req.Filters = append(req.Filters, item)
+	}
+	for _, s := range strings.Split(values.Get(FilterParamSort), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if strings.HasPrefix(s, "-") {
+			req.Sorts = append(req.Sorts, &FilterSort{Column: s[1:], Desc: true})
+		} else {
+			req.Sorts = append(req.Sorts, &FilterSort{Column: strings.TrimPrefix(s, "+")})
+		}
+	}
+	var err error
+	if req.PageNumber, err = parseFilterInt(values.Get(FilterParamPage)); err != nil {
+		return nil, err
+	}
+	if req.PageSize, err = parseFilterInt(values.Get(FilterParamPageSize)); err != nil {
+		return nil, err
+	}
+	return rule.Parse(req)
+}
+
+// Parse 根据白名单校验过滤请求，并转换成 Matcher、排序条件和 Pager，过滤值会按照目标字段在 T 中的类型进行转换
+func (rule *FilterRule[T]) Parse(req *FilterRequest) (*FilterQuery, error) {
+	if rule.err != nil {
+		return nil, rule.err
+	}
+	m := NewMatcher()
+	for _, item := range req.Filters {
+		values, err := rawFilterValues(item.Values)
+		if err != nil {
+			return nil, err
+		}
+		if err = rule.addCond(m, item.Column, strings.ToLower(item.Op), values); err != nil {
+			return nil, err
+		}
+	}
+
+	var orders []*Order
+	for _, sort := range req.Sorts {
+		if !rule.sortable[sort.Column] {
+			return nil, fmt.Errorf("sort by %q is not allowed", sort.Column)
+		}
+		orders = append(orders, &Order{ColumnName: sort.Column, Desc: sort.Desc})
+	}
+
+	pager, err := rule.buildPager(req.PageNumber, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FilterQuery{
+		Matcher: m,
+		Orders:  orders,
+		Pager:   pager,
+	}, nil
+}
+
+func (rule *FilterRule[T]) addCond(m Matcher, column string, op string, values []string) error {
+	if !rule.allowedOps[column][op] {
+		return fmt.Errorf("filter %q on column %q is not allowed", op, column)
+	}
+	switch op {
+	case FilterOpNull, FilterOpNotNull:
+		m.Null(column, op == FilterOpNotNull)
+		return nil
+	case FilterOpLike:
+		if len(values) != 1 || values[0] == "" {
+			return fmt.Errorf("filter %s on column %q needs one value", op, column)
+		}
+		m.Contains(column, values[0])
+		return nil
+	case FilterOpIn, FilterOpNotIn:
+		if len(values) == 0 {
+			return fmt.Errorf("filter %s on column %q needs values", op, column)
+		}
+		converted := make([]any, len(values))
+		for i, v := range values {
+			value, err := convertFilterValue(rule.meta, column, v)
+			if err != nil {
+				return err
+			}
+			converted[i] = value
+		}
+		if op == FilterOpIn {
+			m.In(column, converted)
+		} else {
+			m.NotIn(column, converted)
+		}
+		return nil
+	case FilterOpBetween:
+		if len(values) != 2 || (values[0] == "" && values[1] == "") {
+			return fmt.Errorf("filter %s on column %q needs two values", op, column)
+		}
+		var start, end any
+		var err error
+		if values[0] != "" {
+			if start, err = convertFilterValue(rule.meta, column, values[0]); err != nil {
+				return err
+			}
+		}
+		if values[1] != "" {
+			if end, err = convertFilterValue(rule.meta, column, values[1]); err != nil {
+				return err
+			}
+		}
+		m.Between(column, start, end)
+		return nil
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("filter %s on column %q needs one value", op, column)
+	}
+	value, err := convertFilterValue(rule.meta, column, values[0])
+	if err != nil {
+		return err
+	}
+	switch op {
+	case FilterOpEq:
+		m.Eq(column, value)
+	case FilterOpNe:
+		m.Ne(column, value)
+	case FilterOpLt:
+		m.Lt(column, value)
+	case FilterOpLte:
+		m.Lte(column, value)
+	case FilterOpGt:
+		m.Gt(column, value)
+	case FilterOpGte:
+		m.Gte(column, value)
+	default:
+		return fmt.Errorf("unsupported filter %q", op)
+	}
+	return nil
+}
+
+func (rule *FilterRule[T]) buildPager(pageNumber int, pageSize int) (*Pager, error) {
+	if pageNumber == 0 {
+		pageNumber = 1
+	}
+	if pageNumber < 0 || pageSize < 0 {
+		return nil, errors.New("page number and page size must be greater than 0")
+	}
+	if pageSize == 0 {
+		pageSize = rule.defaultPageSize
+	}
+	if rule.maxPageSize > 0 && pageSize > rule.maxPageSize {
+		pageSize = rule.maxPageSize
+	}
+	return NewPager(pageSize, pageNumber), nil
+}
+
+func parseFilterClause(clause string) (*FilterItem, error) {
+	parts := strings.SplitN(clause, ":", 3)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid filter clause %q", clause)
+	}
+	item := &FilterItem{
+		Column: strings.TrimSpace(parts[0]),
+		Op:     strings.TrimSpace(parts[1]),
+	}
+	if len(parts) == 2 {
+		return item, nil
+	}
+	var values []string
+	switch strings.ToLower(item.Op) {
+	case FilterOpIn, FilterOpNotIn, FilterOpBetween:
+		values = strings.Split(parts[2], ",")
+	default:
+		values = []string{parts[2]}
+	}
+	for _, v := range values {
+		raw, _ := json.Marshal(v)
+		item.Values = append(item.Values, raw)
+	}
+	return item, nil
+}
+
+func parseFilterInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// rawFilterValues 把json值统一转换成字符串，json字符串去掉引号，数字和布尔值保持原样
+func rawFilterValues(raws []json.RawMessage) ([]string, error) {
+	values := make([]string, len(raws))
+	for i, raw := range raws {
+		if len(raw) > 0 && raw[0] == '"' {
+			if err := json.Unmarshal(raw, &values[i]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		values[i] = string(raw)
+	}
+	return values, nil
+}
+
+// convertFilterValue 按照 column 对应的 T 中 field 的类型转换过滤值
+func convertFilterValue[T any](meta *TableMeta[T], column string, raw string) (any, error) {
+	v, err := convertStringToFieldType(meta.LookupFieldFunc(column, new(T), true), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for column %q: %w", raw, column, err)
+	}
+	return v, nil
+}
+
+func convertStringToFieldType(fieldPoint any, raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	switch fieldPoint.(type) {
+	case *string, *ttypes.NilableString:
+		return raw, nil
+	case *[]byte:
+		return []byte(raw), nil
+	case *int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case *int32:
+		v, err := strconv.ParseInt(raw, 10, 32)
+		return int32(v), err
+	case *int16:
+		v, err := strconv.ParseInt(raw, 10, 16)
+		return int16(v), err
+	case *int8:
+		v, err := strconv.ParseInt(raw, 10, 8)
+		return int8(v), err
+	case *int:
+		return strconv.Atoi(raw)
+	case *uint64:
+		return strconv.ParseUint(raw, 10, 64)
+	case *uint32:
+		v, err := strconv.ParseUint(raw, 10, 32)
+		return uint32(v), err
+	case *float64:
+		return strconv.ParseFloat(raw, 64)
+	case *float32:
+		v, err := strconv.ParseFloat(raw, 32)
+		return float32(v), err
+	case *bool:
+		return strconv.ParseBool(raw)
+	case *decimal.Decimal:
+		return decimal.NewFromString(raw)
+	case *ttypes.NormalDate, *ttypes.NilableDate:
+		d, err := ttypes.ParseNormalDate(raw)
+		if err != nil {
+			return nil, err
+		}
+		return *d, nil
+	case *ttypes.NormalDatetime, *ttypes.NilableDatetime, *time.Time:
+		return parseFilterDatetime(raw)
+	}
+	return nil, fmt.Errorf("unsupported field type %T", fieldPoint)
+}
+
+// parseFilterDatetime 时间类型的字段也接受只有日期的值，比如 2024-01-01，表示当天的 00:00:00
+func parseFilterDatetime(raw string) (ttypes.NormalDatetime, error) {
+	dt, err := ttypes.ParseNormalDatetime(raw)
+	if err == nil {
+		return *dt, nil
+	}
+	d, dErr := ttypes.ParseNormalDate(raw)
+	if dErr != nil {
+		return ttypes.NormalDatetime{}, err
+	}
+	return ttypes.NormalDatetime(*d), nil
+}
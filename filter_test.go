@@ -0,0 +1,112 @@
+package daog
+
+import (
+	"errors"
+	"github.com/rolandhe/daog/ttypes"
+	"testing"
+)
+
+type filterSample struct {
+	Id       int64
+	Name     string
+	Status   int32
+	CreateAt ttypes.NormalDatetime
+}
+
+var filterSampleMeta = &TableMeta[filterSample]{
+	Table:   "filter_sample",
+	Columns: []string{"id", "name", "status", "create_at"},
+	LookupFieldFunc: func(columnName string, ins *filterSample, point bool) any {
+		switch columnName {
+		case "id":
+			if point {
+				return &ins.Id
+			}
+			return ins.Id
+		case "name":
+			if point {
+				return &ins.Name
+			}
+			return ins.Name
+		case "status":
+			if point {
+				return &ins.Status
+			}
+			return ins.Status
+		case "create_at":
+			if point {
+				return &ins.CreateAt
+			}
+			return ins.CreateAt
+		}
+		return nil
+	},
+}
+
+func newFilterSampleRule() *FilterRule[filterSample] {
+	return NewFilterRule(filterSampleMeta).
+		Allow("status", FilterOpEq, FilterOpIn).
+		Allow("name", FilterOpLike).
+		Allow("create_at", FilterOpBetween).
+		AllowSort("id", "create_at")
+}
+
+func TestFilterRuleParseQueryString(t *testing.T) {
+	q, err := newFilterSampleRule().ParseQueryString("filter=status:in:1,2;name:like:abc;create_at:between:2024-01-01,2024-02-01 10:00:00&sort=-create_at,id&page=2&size=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, args, err := q.Matcher.ToSQL(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "status in (?,?) and name like ? escape '!' and create_at between ? and ?" {
+		t.Error(sql)
+	}
+	if len(args) != 5 || args[0] != int32(1) || args[2] != "%abc%" {
+		t.Error(args)
+	}
+	if _, ok := args[3].(ttypes.NormalDatetime); !ok {
+		t.Errorf("%T", args[3])
+	}
+	if len(q.Orders) != 2 || q.Orders[0].ColumnName != "create_at" || !q.Orders[0].Desc || q.Orders[1].Desc {
+		t.Error("bad orders")
+	}
+	if q.Pager.PageNumber != 2 || q.Pager.PageSize != 10 {
+		t.Error("bad pager")
+	}
+}
+
+func TestFilterRuleParseJSON(t *testing.T) {
+	q, err := newFilterSampleRule().ParseJSON([]byte(`{"filters":[{"column":"status","op":"eq","values":[3]}],"sorts":[{"column":"id","desc":true}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, args, _ := q.Matcher.ToSQL(nil)
+	if sql != "status = ?" || args[0] != int32(3) {
+		t.Error(sql, args)
+	}
+	if q.Pager == nil || q.Pager.PageNumber != 1 || q.Pager.PageSize != defaultFilterPageSize {
+		t.Error("missing paging parameters should use the default pager", q.Pager)
+	}
+}
+
+func TestFilterRuleRejects(t *testing.T) {
+	rule := newFilterSampleRule()
+	cases := []string{
+		"filter=id:eq:1",
+		"filter=status:like:1",
+		"filter=status:eq:abc",
+		"sort=name",
+	}
+	for _, c := range cases {
+		if _, err := rule.ParseQueryString(c); err == nil {
+			t.Errorf("%s should be rejected", c)
+		}
+	}
+
+	_, err := NewFilterRule(filterSampleMeta).Allow("id;drop", FilterOpEq).ParseQueryString("")
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,152 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// QueryHints 查询提示，通过 View.Hints 设置，用于在优化器选错索引等场景下干预执行计划，生成的sql形如:
+//
+//	select /*+ MAX_EXECUTION_TIME(1000) */ straight_join sql_no_cache a,b from tab force index (idx_a) where ...
+//
+// 查询通过 View.Hints 传入，比如 QueryPageListMatcherWithViewObj、QueryPageListMatcherWithViewObjForUpdate、QueryPageWithOptions，
+// Count、Exists 及 ExistsForUpdate 请使用 CountWithHints、ExistsWithHints 及 ExistsForUpdateWithHints。
+// 只接收 viewColumns 的 XxxForUpdate 函数不会带上提示
+type QueryHints struct {
+	// Optimizer 优化器提示，比如 MAX_EXECUTION_TIME(1000)，生成在 select 之后的 /*+ ... */ 注释中
+	Optimizer []string
+	// IndexHints 索引提示，生成在表名之后
+	IndexHints []*IndexHint
+	// StraightJoin 生成 straight_join
+	StraightJoin bool
+	// NoCache 生成 sql_no_cache
+	NoCache bool
+}
+
+// IndexHint 索引提示，Kind 是 use、force 或者 ignore
+type IndexHint struct {
+	Kind    string
+	Indexes []string
+}
+
+// NewQueryHints 创建查询提示
+func NewQueryHints() *QueryHints {
+	return &QueryHints{}
+}
+
+// UseIndex 增加 use index (...) 提示
+func (hints *QueryHints) UseIndex(indexes ...string) *QueryHints {
+	hints.IndexHints = append(hints.IndexHints, &IndexHint{"use", indexes})
+	return hints
+}
+
+// ForceIndex 增加 force index (...) 提示
+func (hints *QueryHints) ForceIndex(indexes ...string) *QueryHints {
+	hints.IndexHints = append(hints.IndexHints, &IndexHint{"force", indexes})
+	return hints
+}
+
+// IgnoreIndex 增加 ignore index (...) 提示
+func (hints *QueryHints) IgnoreIndex(indexes ...string) *QueryHints {
+	hints.IndexHints = append(hints.IndexHints, &IndexHint{"ignore", indexes})
+	return hints
+}
+
+// OptimizerHint 增加优化器提示，比如 MAX_EXECUTION_TIME(1000)、NO_INDEX_MERGE(t)，不需要 /*+ */
+func (hints *QueryHints) OptimizerHint(hint string) *QueryHints {
+	hints.Optimizer = append(hints.Optimizer, hint)
+	return hints
+}
+
+// MaxExecutionTime 增加 MAX_EXECUTION_TIME 优化器提示，单位是毫秒
+func (hints *QueryHints) MaxExecutionTime(millis int) *QueryHints {
+	return hints.OptimizerHint("MAX_EXECUTION_TIME(" + strconv.Itoa(millis) + ")")
+}
+
+// WithStraightJoin 设置 straight_join
+func (hints *QueryHints) WithStraightJoin() *QueryHints {
+	hints.StraightJoin = true
+	return hints
+}
+
+// WithNoCache 设置 sql_no_cache
+func (hints *QueryHints) WithNoCache() *QueryHints {
+	hints.NoCache = true
+	return hints
+}
+
+// WithHints 为视图设置查询提示，返回视图本身
+func (view *View) WithHints(hints *QueryHints) *View {
+	view.Hints = hints
+	return view
+}
+
+// validate 校验提示内容，防止通过提示注入sql
+func (hints *QueryHints) validate() error {
+	for _, hint := range hints.Optimizer {
+		if hint == "" || strings.ContainsAny(hint, ";#\n\r") || strings.Contains(hint, "*/") || strings.Contains(hint, "/*") || strings.Contains(hint, "--") {
+			return errors.New("invalid optimizer hint: " + hint)
+		}
+	}
+	for _, indexHint := range hints.IndexHints {
+		switch indexHint.Kind {
+		case "use", "force", "ignore":
+		default:
+			return errors.New("invalid index hint kind: " + indexHint.Kind)
+		}
+		if len(indexHint.Indexes) == 0 && indexHint.Kind != "use" {
+			return errors.New(indexHint.Kind + " index hint needs at least one index")
+		}
+		for _, index := range indexHint.Indexes {
+			if !isSimpleIdentifier(index) {
+				return errors.New("invalid index name: " + index)
+			}
+		}
+	}
+	return nil
+}
+
+// optimizerComment 返回紧跟在 select 关键字之后的优化器提示注释
+func (hints *QueryHints) optimizerComment() string {
+	if len(hints.Optimizer) == 0 {
+		return ""
+	}
+	return " /*+ " + strings.Join(hints.Optimizer, " ") + " */"
+}
+
+// selectModifiers 返回 distinct 之后、字段列表之前的 straight_join、sql_no_cache
+func (hints *QueryHints) selectModifiers() string {
+	var builder strings.Builder
+	if hints.StraightJoin {
+		builder.WriteString(" straight_join")
+	}
+	if hints.NoCache {
+		builder.WriteString(" sql_no_cache")
+	}
+	return builder.String()
+}
+
+// hintedSelectBase 生成 select columns from tableName，并在对应位置加入提示，hints 可以为nil，用于 count、exists 等没有 View 的查询
+func hintedSelectBase(hints *QueryHints, columns string, tableName string) string {
+	if hints == nil {
+		return "select " + columns + " from " + tableName
+	}
+	return "select" + hints.optimizerComment() + hints.selectModifiers() + " " + columns + " from " + tableName + hints.tableSuffix()
+}
+
+// tableSuffix 返回表名之后的索引提示
+func (hints *QueryHints) tableSuffix() string {
+	var builder strings.Builder
+	for _, indexHint := range hints.IndexHints {
+		builder.WriteString(" ")
+		builder.WriteString(indexHint.Kind)
+		builder.WriteString(" index (")
+		builder.WriteString(strings.Join(indexHint.Indexes, ","))
+		builder.WriteString(")")
+	}
+	return builder.String()
+}
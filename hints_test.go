@@ -0,0 +1,68 @@
+package daog
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryHints(t *testing.T) {
+	hints := NewQueryHints().MaxExecutionTime(1000).WithStraightJoin().WithNoCache().UseIndex("idx_name", "idx_status")
+	sql, _, err := BuildSelect(filterSampleMeta, NewMatcher().Eq("status", 1), nil, nil, NewView([]string{"id", "name"}).WithHints(hints))
+	expected := "select /*+ MAX_EXECUTION_TIME(1000) */ straight_join sql_no_cache id,name from filter_sample use index (idx_name,idx_status) where status = ?"
+	if err != nil || sql != expected {
+		t.Error(sql, err)
+	}
+
+	invalid := []*QueryHints{
+		NewQueryHints().OptimizerHint("BKA(t) */ drop table t; /*"),
+		NewQueryHints().OptimizerHint(""),
+		NewQueryHints().ForceIndex(),
+		NewQueryHints().IgnoreIndex("idx_a) union select 1 #"),
+		{IndexHints: []*IndexHint{{Kind: "bad", Indexes: []string{"idx_a"}}}},
+	}
+	for _, h := range invalid {
+		if _, _, err = BuildSelect(filterSampleMeta, nil, nil, nil, NewView([]string{"id"}).WithHints(h)); err == nil {
+			t.Error("hints should be rejected", h)
+		}
+	}
+}
+
+func TestCountExistsForUpdateHints(t *testing.T) {
+	db := &fakeDB{columns: []string{"c"}, rows: [][]driver.Value{{int64(3)}}}
+	tc := newFakeTc(t, db)
+	m := NewMatcher().Eq("status", 1)
+	hints := NewQueryHints().MaxExecutionTime(100).ForceIndex("idx_status")
+
+	count, err := CountWithHints(tc, m, filterSampleMeta, hints)
+	expected := "select /*+ MAX_EXECUTION_TIME(100) */ count(*) from filter_sample force index (idx_status) where status = ?"
+	if err != nil || count != 3 || db.lastQuery() != expected {
+		t.Error(count, err, db.lastQuery())
+	}
+
+	exists, err := ExistsWithHints(tc, m, filterSampleMeta, NewQueryHints().UseIndex("idx_status"))
+	expected = "select 1 from filter_sample use index (idx_status) where status = ? limit 1"
+	if err != nil || !exists || db.lastQuery() != expected {
+		t.Error(exists, err, db.lastQuery())
+	}
+
+	exists, err = ExistsForUpdateWithHints(tc, m, filterSampleMeta, NewQueryHints().ForceIndex("idx_status"), false)
+	expected = "select 1 from filter_sample force index (idx_status) where status = ? limit 1 for update"
+	if err != nil || !exists || db.lastQuery() != expected {
+		t.Error(exists, err, db.lastQuery())
+	}
+
+	db.columns = []string{"id"}
+	view := NewView([]string{"id"}).WithHints(NewQueryHints().ForceIndex("idx_status"))
+	list, err := QueryPageListMatcherWithViewObjForUpdate(tc, m, filterSampleMeta, view, nil, true, NewOrder("id"))
+	expected = "select id from filter_sample force index (idx_status) where status = ? order by id for update skip locked"
+	if err != nil || len(list) != 1 || db.lastQuery() != expected {
+		t.Error(list, err, db.lastQuery())
+	}
+
+	if _, err = CountWithHints(tc, m, filterSampleMeta, NewQueryHints().ForceIndex()); err == nil {
+		t.Error("invalid hints should be rejected")
+	}
+	if count, err = Count(tc, nil, filterSampleMeta); err != nil || db.lastQuery() != "select count(*) from filter_sample" {
+		t.Error(count, err, db.lastQuery())
+	}
+}
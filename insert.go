@@ -5,6 +5,7 @@
 package daog
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -22,6 +23,14 @@ func Insert[T any](tc *TransContext, ins *T, meta *TableMeta[T]) (int64, error)
 		}
 	}
 
+	var setAutoId func(lastId int64)
+	if meta.AutoColumn != "" {
+		var err error
+		if setAutoId, err = autoIdSetter(meta.LookupFieldFunc(meta.AutoColumn, ins, true)); err != nil {
+			return 0, err
+		}
+	}
+
 	exclude := meta.shouldExcludeColumns(ins, false)
 
 	var insertColumns []string
@@ -55,19 +64,46 @@ func Insert[T any](tc *TransContext, ins *T, meta *TableMeta[T]) (int64, error)
 	builder.WriteString(")")
 	sql := builder.String()
 	args := meta.ExtractFieldValues(ins, false, exclude)
-	affect, lastId, err := execInsert(tc, sql, args, meta.AutoColumn != "")
+	affect, lastId, err := execInsert(tc, sql, args, setAutoId != nil)
 	if err != nil {
 		return 0, err
 	}
+	markTableModified(tc, meta)
 
-	if meta.AutoColumn != "" {
-		autoAddr := meta.LookupFieldFunc(meta.AutoColumn, ins, true)
-		*(autoAddr.(*int64)) = lastId
+	if setAutoId != nil {
+		setAutoId(lastId)
 	}
 
 	return affect, err
 }
 
+// autoIdSetter 返回把自增长id赋值给自增长字段的函数，自增长字段必须是整数类型，否则返回错误，在执行 insert 之前检查
+func autoIdSetter(autoAddr any) (func(lastId int64), error) {
+	switch p := autoAddr.(type) {
+	case *int64:
+		return func(lastId int64) { *p = lastId }, nil
+	case *int:
+		return func(lastId int64) { *p = int(lastId) }, nil
+	case *int32:
+		return func(lastId int64) { *p = int32(lastId) }, nil
+	case *int16:
+		return func(lastId int64) { *p = int16(lastId) }, nil
+	case *int8:
+		return func(lastId int64) { *p = int8(lastId) }, nil
+	case *uint64:
+		return func(lastId int64) { *p = uint64(lastId) }, nil
+	case *uint32:
+		return func(lastId int64) { *p = uint32(lastId) }, nil
+	case *uint16:
+		return func(lastId int64) { *p = uint16(lastId) }, nil
+	case *uint8:
+		return func(lastId int64) { *p = uint8(lastId) }, nil
+	case *uint:
+		return func(lastId int64) { *p = uint(lastId) }, nil
+	}
+	return nil, fmt.Errorf("auto increment column of type %T is not an integer", autoAddr)
+}
+
 func execInsert(tc *TransContext, sql string, args []any, auto bool) (int64, int64, error) {
 	err := tc.check()
 	if err != nil {
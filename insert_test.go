@@ -0,0 +1,94 @@
+package daog
+
+import "testing"
+
+type smallIdSample struct {
+	Id   int8
+	Name string
+}
+
+type stringIdSample struct {
+	Id   string
+	Name string
+}
+
+func newAutoIdMeta[T any](lookup func(columnName string, ins *T, point bool) any) *TableMeta[T] {
+	return &TableMeta[T]{
+		Table:           "auto_id_sample",
+		Columns:         []string{"id", "name"},
+		AutoColumn:      "id",
+		LookupFieldFunc: lookup,
+	}
+}
+
+func TestInsertAutoId(t *testing.T) {
+	db := &fakeDB{lastInsertId: 12}
+	tc := newFakeTc(t, db)
+	meta := newAutoIdMeta(func(columnName string, ins *smallIdSample, point bool) any {
+		switch columnName {
+		case "id":
+			if point {
+				return &ins.Id
+			}
+			return ins.Id
+		case "name":
+			if point {
+				return &ins.Name
+			}
+			return ins.Name
+		}
+		return nil
+	})
+	ins := &smallIdSample{Name: "a"}
+	if affect, err := Insert(tc, ins, meta); err != nil || affect != 1 || ins.Id != 12 {
+		t.Error(affect, err, ins.Id)
+	}
+	if db.lastQuery() != "insert into auto_id_sample(name) values(?)" {
+		t.Error(db.lastQuery())
+	}
+}
+
+func TestInsertUnsupportedAutoIdType(t *testing.T) {
+	db := &fakeDB{lastInsertId: 12}
+	tc := newFakeTc(t, db)
+	meta := newAutoIdMeta(func(columnName string, ins *stringIdSample, point bool) any {
+		switch columnName {
+		case "id":
+			if point {
+				return &ins.Id
+			}
+			return ins.Id
+		case "name":
+			if point {
+				return &ins.Name
+			}
+			return ins.Name
+		}
+		return nil
+	})
+	if _, err := Insert(tc, &stringIdSample{Name: "a"}, meta); err == nil {
+		t.Error("string auto increment column should fail")
+	}
+	if len(db.queries) != 0 {
+		t.Error("insert should not be executed", db.queries)
+	}
+}
+
+func TestAutoIdSetter(t *testing.T) {
+	var i16 int16
+	var u8 uint8
+	var u16 uint16
+	for _, addr := range []any{&i16, &u8, &u16} {
+		set, err := autoIdSetter(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set(7)
+	}
+	if i16 != 7 || u8 != 7 || u16 != 7 {
+		t.Error(i16, u8, u16)
+	}
+	if _, err := autoIdSetter(new(float64)); err == nil {
+		t.Error("float auto increment column should fail")
+	}
+}
@@ -0,0 +1,342 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	joinTypeInner = "inner join"
+	joinTypeLeft  = "left join"
+)
+
+// JoinQuery 多表关联查询的构建器，R 是每行结果的组合类型，每个参与关联的表对应 R 中一个该表实体的指针，比如:
+//
+//	type GroupUser struct {
+//	    G *dal.GroupInfo
+//	    U *dal.UserInfo
+//	}
+//
+//	q := daog.NewJoinQuery(dal.GroupInfoMeta, "g", nil, func(r *GroupUser) **dal.GroupInfo { return &r.G })
+//	daog.LeftJoin(q, dal.UserInfoMeta, "u", daog.JoinOn("g.id", "u.group_id"), nil, func(r *GroupUser) **dal.UserInfo { return &r.U })
+//	q.Where(daog.NewMatcher().Eq("g.name", "roland")).OrderBy(daog.NewOrder("g.id"))
+//	list, err := daog.QueryJoin(tc, q)
+//
+// 生成的 sql 中每个字段都使用表别名限定，Where 和 OrderBy 中的字段也需要使用 别名.字段名 的形式。
+// 每张表的数据通过其 TableMeta.LookupFieldFunc 填充，不使用反射; left join 的表在没有匹配数据时，R 中对应的指针为 nil，
+// 是否匹配根据该表的主键字段是否为 NULL 判断，视图中没有主键字段时会自动加入
+type JoinQuery[R any] struct {
+	tables []*joinTable[R]
+	where  Matcher
+	orders []*Order
+	pager  *Pager
+	err    error
+}
+
+type joinTable[R any] struct {
+	joinType string
+	alias    string
+	on       SQLCond
+	columns  []string
+	// keyIndexes 主键字段在 columns 中的位置，left join 时用于判断是否匹配到数据
+	keyIndexes []int
+	tableName  func(ctx context.Context) string
+	hasColumn  func(column string) bool
+	checkView  func() error
+	// bind 创建表实体对象，把它设置到 r 上，并返回用于 Scan 的 field 指针
+	bind func(r *R) []any
+	// unbind 把 r 上该表的实体对象设置为 nil
+	unbind func(r *R)
+}
+
+// NewJoinQuery 创建关联查询，meta 是主表(from 后面的表)，alias 是主表的别名，view 是主表需要查询的字段，可以为 nil，
+// field 返回 R 中用于存放主表实体对象的字段的地址
+func NewJoinQuery[R any, T any](meta *TableMeta[T], alias string, view *View, field func(r *R) **T) *JoinQuery[R] {
+	q := &JoinQuery[R]{}
+	q.addTable(newJoinTable(meta, "", alias, nil, view, field))
+	return q
+}
+
+// InnerJoin 以 inner join 方式关联 meta 对应的表，on 是关联条件，一般使用 JoinOn 生成，也可以是包含 JoinOn 的 Matcher
+func InnerJoin[R any, T any](q *JoinQuery[R], meta *TableMeta[T], alias string, on SQLCond, view *View, field func(r *R) **T) *JoinQuery[R] {
+	q.addTable(newJoinTable(meta, joinTypeInner, alias, on, view, field))
+	return q
+}
+
+// LeftJoin 以 left join 方式关联 meta 对应的表，没有匹配数据时 R 中对应的字段为 nil
+func LeftJoin[R any, T any](q *JoinQuery[R], meta *TableMeta[T], alias string, on SQLCond, view *View, field func(r *R) **T) *JoinQuery[R] {
+	q.addTable(newJoinTable(meta, joinTypeLeft, alias, on, view, field))
+	return q
+}
+
+// JoinOn 生成两个字段相等的关联条件，比如 JoinOn("g.id", "u.group_id") 生成 g.id = u.group_id
+func JoinOn(leftColumn string, rightColumn string) SQLCond {
+	return &joinOnCond{leftColumn, rightColumn}
+}
+
+// Where 设置查询条件，字段需要使用 别名.字段名 的形式
+func (q *JoinQuery[R]) Where(m Matcher) *JoinQuery[R] {
+	q.where = m
+	return q
+}
+
+// OrderBy 设置排序条件，字段需要使用 别名.字段名 的形式
+func (q *JoinQuery[R]) OrderBy(orders ...*Order) *JoinQuery[R] {
+	q.orders = orders
+	return q
+}
+
+// Page 设置分页条件
+func (q *JoinQuery[R]) Page(pager *Pager) *JoinQuery[R] {
+	q.pager = pager
+	return q
+}
+
+// QueryJoin 执行关联查询，返回组合结果
+func QueryJoin[R any](tc *TransContext, q *JoinQuery[R]) ([]*R, error) {
+	stmt, args, err := q.toSQL(tc.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*R
+	err = queryRowsCore(tc, stmt, args, func(rows *sql.Rows) error {
+		for rows.Next() {
+			r := new(R)
+			var scanFields []any
+			var nullables [][]*nullableScanner
+			for _, table := range q.tables {
+				fields := table.bind(r)
+				if table.joinType != joinTypeLeft {
+					scanFields = append(scanFields, fields...)
+					nullables = append(nullables, nil)
+					continue
+				}
+				wrapped := make([]*nullableScanner, len(fields))
+				for i, f := range fields {
+					wrapped[i] = &nullableScanner{dest: f}
+					scanFields = append(scanFields, wrapped[i])
+				}
+				nullables = append(nullables, wrapped)
+			}
+			if err := rows.Scan(scanFields...); err != nil {
+				return err
+			}
+			for i, table := range q.tables {
+				if nullables[i] != nil && keysNull(nullables[i], table.keyIndexes) {
+					table.unbind(r)
+				}
+			}
+			ret = append(ret, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func newJoinTable[R any, T any](meta *TableMeta[T], joinType string, alias string, on SQLCond, view *View, field func(r *R) **T) *joinTable[R] {
+	columns := meta.resolveViewColumns(view)
+	var keyIndexes []int
+	if joinType == joinTypeLeft {
+		columns, keyIndexes = withKeyColumns(columns, meta.keyColumns())
+	}
+	return &joinTable[R]{
+		joinType:   joinType,
+		alias:      alias,
+		on:         on,
+		columns:    columns,
+		keyIndexes: keyIndexes,
+		tableName: func(ctx context.Context) string {
+			return GetTableName(ctx, meta)
+		},
+		hasColumn: meta.HasColumn,
+		checkView: func() error {
+			return checkQueryColumns(meta, view, nil, nil)
+		},
+		bind: func(r *R) []any {
+			ins := new(T)
+			*field(r) = ins
+			return meta.ExtractFieldValuesByColumns(ins, true, columns)
+		},
+		unbind: func(r *R) {
+			*field(r) = nil
+		},
+	}
+}
+
+func (q *JoinQuery[R]) addTable(table *joinTable[R]) {
+	if q.err != nil {
+		return
+	}
+	if !isSimpleIdentifier(table.alias) {
+		q.err = errors.New("invalid join table alias " + table.alias)
+		return
+	}
+	for _, t := range q.tables {
+		if t.alias == table.alias {
+			q.err = errors.New("duplicate join table alias " + table.alias)
+			return
+		}
+	}
+	q.tables = append(q.tables, table)
+}
+
+func (q *JoinQuery[R]) toSQL(ctx context.Context) (string, []any, error) {
+	if q.err != nil {
+		return "", nil, q.err
+	}
+	if err := q.checkColumns(); err != nil {
+		return "", nil, err
+	}
+
+	var selects []string
+	for _, table := range q.tables {
+		for _, c := range table.columns {
+			selects = append(selects, table.alias+"."+c)
+		}
+	}
+
+	var builder strings.Builder
+	var args []any
+	builder.WriteString("select ")
+	builder.WriteString(strings.Join(selects, ","))
+	builder.WriteString(" from ")
+	for i, table := range q.tables {
+		if i > 0 {
+			builder.WriteString(" ")
+			builder.WriteString(table.joinType)
+			builder.WriteString(" ")
+		}
+		builder.WriteString(table.tableName(ctx))
+		builder.WriteString(" ")
+		builder.WriteString(table.alias)
+		if i == 0 {
+			continue
+		}
+		if table.on == nil {
+			return "", nil, errors.New("join table " + table.alias + " has no on condition")
+		}
+		condi, a, err := table.on.ToSQL(args)
+		if err != nil {
+			return "", nil, err
+		}
+		if condi == "" {
+			return "", nil, errors.New("join table " + table.alias + " has no on condition")
+		}
+		builder.WriteString(" on ")
+		builder.WriteString(condi)
+		args = a
+	}
+	if q.where != nil {
+		condi, a, err := q.where.ToSQL(args)
+		if err != nil {
+			return "", nil, err
+		}
+		if condi != "" {
+			builder.WriteString(" where ")
+			builder.WriteString(condi)
+			args = a
+		}
+	}
+	if q.pager != nil {
+		if err := q.pager.Validate(); err != nil {
+			return "", nil, err
+		}
+	}
+	builder.WriteString(buildQuerySuffix(q.pager, q.orders))
+	return builder.String(), args, nil
+}
+
+// checkColumns 严格字段校验模式下，校验每张表的视图字段，以及 on、where、order 中引用的 别名.字段名
+func (q *JoinQuery[R]) checkColumns() error {
+	if !StrictColumnCheck {
+		return nil
+	}
+	var refers []string
+	for _, table := range q.tables {
+		if err := table.checkView(); err != nil {
+			return err
+		}
+		if referrer, ok := table.on.(columnsReferrer); ok {
+			refers = referrer.referColumns(refers)
+		}
+	}
+	if referrer, ok := q.where.(columnsReferrer); ok {
+		refers = referrer.referColumns(refers)
+	}
+	for _, order := range q.orders {
+		refers = append(refers, order.ColumnName)
+	}
+	for _, refer := range refers {
+		if !q.hasQualifiedColumn(refer) {
+			return fmt.Errorf("%w: join column %q is not an alias.column of the joined tables", ErrUnknownColumn, refer)
+		}
+	}
+	return nil
+}
+
+func (q *JoinQuery[R]) hasQualifiedColumn(refer string) bool {
+	alias, column, ok := strings.Cut(refer, ".")
+	if !ok {
+		return false
+	}
+	for _, table := range q.tables {
+		if table.alias == alias {
+			return table.hasColumn(column)
+		}
+	}
+	return false
+}
+
+// withKeyColumns 返回包含所有主键字段的字段列表，以及主键字段在其中的位置，原 columns 不变
+func withKeyColumns(columns []string, keyColumns []string) ([]string, []int) {
+	ret := columns
+	indexes := make([]int, 0, len(keyColumns))
+	for _, key := range keyColumns {
+		index := -1
+		for i, c := range ret {
+			if c == key {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			ret = append(ret[:len(ret):len(ret)], key)
+			index = len(ret) - 1
+		}
+		indexes = append(indexes, index)
+	}
+	return ret, indexes
+}
+
+// keysNull 主键字段都为 NULL 时表示 left join 没有匹配到数据
+func keysNull(scanners []*nullableScanner, keyIndexes []int) bool {
+	for _, i := range keyIndexes {
+		if !scanners[i].null {
+			return false
+		}
+	}
+	return true
+}
+
+type joinOnCond struct {
+	leftColumn  string
+	rightColumn string
+}
+
+func (on *joinOnCond) referColumns(columns []string) []string {
+	return append(columns, on.leftColumn, on.rightColumn)
+}
+
+func (on *joinOnCond) ToSQL(args []any) (string, []any, error) {
+	return on.leftColumn + " = " + on.rightColumn, args, nil
+}
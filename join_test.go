@@ -0,0 +1,129 @@
+package daog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rolandhe/daog/ttypes"
+)
+
+type joinUser struct {
+	Id      int64
+	GroupId int64
+	Nick    ttypes.NilableString
+}
+
+var joinUserMeta = &TableMeta[joinUser]{
+	Table:   "join_user",
+	Columns: []string{"id", "group_id", "nick"},
+	LookupFieldFunc: func(columnName string, ins *joinUser, point bool) any {
+		switch columnName {
+		case "id":
+			if point {
+				return &ins.Id
+			}
+			return ins.Id
+		case "group_id":
+			if point {
+				return &ins.GroupId
+			}
+			return ins.GroupId
+		case "nick":
+			if point {
+				return &ins.Nick
+			}
+			return ins.Nick
+		}
+		return nil
+	},
+}
+
+type sampleWithUser struct {
+	S *filterSample
+	U *joinUser
+}
+
+func newSampleUserJoin(userView *View) *JoinQuery[sampleWithUser] {
+	q := NewJoinQuery(filterSampleMeta, "s", NewView([]string{"id", "name"}), func(r *sampleWithUser) **filterSample { return &r.S })
+	return LeftJoin(q, joinUserMeta, "u", NewMatcher().AddCond(JoinOn("s.id", "u.group_id")).Gt("u.id", 5), userView, func(r *sampleWithUser) **joinUser { return &r.U })
+}
+
+func TestJoinQueryToSQL(t *testing.T) {
+	q := newSampleUserJoin(NewView([]string{"nick"})).
+		Where(NewMatcher().Eq("s.status", 1)).
+		OrderBy(NewDescOrder("s.id")).
+		Page(NewPager(10, 2))
+	sql, args, err := q.toSQL(context.Background())
+	expected := "select s.id,s.name,u.nick,u.id from filter_sample s left join join_user u on s.id = u.group_id and u.id > ? where s.status = ? order by s.id desc limit 10,10"
+	if err != nil || sql != expected || !reflect.DeepEqual(args, []any{5, 1}) {
+		t.Error(sql, args, err)
+	}
+
+	inner := InnerJoin(NewJoinQuery(filterSampleMeta, "s", nil, func(r *sampleWithUser) **filterSample { return &r.S }),
+		joinUserMeta, "u", JoinOn("s.id", "u.group_id"), NewView([]string{"nick"}), func(r *sampleWithUser) **joinUser { return &r.U })
+	sql, _, err = inner.toSQL(context.Background())
+	if err != nil || sql != "select s.id,s.name,s.status,s.create_at,u.nick from filter_sample s inner join join_user u on s.id = u.group_id" {
+		t.Error(sql, err)
+	}
+
+	dup := LeftJoin(newSampleUserJoin(nil), joinUserMeta, "u", JoinOn("s.id", "u.group_id"), nil, func(r *sampleWithUser) **joinUser { return &r.U })
+	if _, _, err = dup.toSQL(context.Background()); err == nil {
+		t.Error("duplicate alias should fail")
+	}
+	noOn := LeftJoin(NewJoinQuery(filterSampleMeta, "s", nil, func(r *sampleWithUser) **filterSample { return &r.S }), joinUserMeta, "u", nil, nil, func(r *sampleWithUser) **joinUser { return &r.U })
+	if _, _, err = noOn.toSQL(context.Background()); err == nil {
+		t.Error("missing on condition should fail")
+	}
+	if _, _, err = newSampleUserJoin(nil).Page(NewPager(0, 1)).toSQL(context.Background()); err != ErrInvalidPager {
+		t.Error(err)
+	}
+}
+
+func TestJoinQueryStrictColumns(t *testing.T) {
+	defer func() {
+		StrictColumnCheck = false
+	}()
+	StrictColumnCheck = true
+	cases := []*JoinQuery[sampleWithUser]{
+		newSampleUserJoin(nil).Where(NewMatcher().Eq("status", 1)),
+		newSampleUserJoin(nil).Where(NewMatcher().Eq("x.status", 1)),
+		newSampleUserJoin(nil).Where(NewMatcher().Eq("u.status", 1)),
+		newSampleUserJoin(nil).OrderBy(NewOrder("s.nick")),
+		newSampleUserJoin(NewView([]string{"name"})),
+	}
+	for i, q := range cases {
+		if _, _, err := q.toSQL(context.Background()); !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("case %d: %v", i, err)
+		}
+	}
+	if _, _, err := newSampleUserJoin(nil).Where(NewMatcher().Eq("u.nick", "a")).OrderBy(NewOrder("s.name")).toSQL(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryJoinLeftMiss(t *testing.T) {
+	db := &fakeDB{
+		columns: []string{"id", "name", "nick", "id"},
+		rows: [][]driver.Value{
+			{int64(1), []byte("a"), []byte("tom"), int64(7)},
+			{int64(2), []byte("b"), nil, int64(8)},
+			{int64(3), []byte("c"), nil, nil},
+		},
+	}
+	list, err := QueryJoin(newFakeTc(t, db), newSampleUserJoin(NewView([]string{"nick"})))
+	if err != nil || len(list) != 3 {
+		t.Fatal(list, err)
+	}
+	if list[0].U == nil || list[0].U.Id != 7 || list[0].U.Nick.String != "tom" {
+		t.Error(list[0].U)
+	}
+	if list[1].U == nil || list[1].U.Id != 8 || list[1].U.Nick.Valid {
+		t.Error("matched row with null selected columns should not be a miss", list[1].U)
+	}
+	if list[2].U != nil || list[2].S.Name != "c" {
+		t.Error("row without key should be a miss", list[2].U)
+	}
+}
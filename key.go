@@ -0,0 +1,126 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import "fmt"
+
+// keyColumns 返回表的主键字段，没有设置 TableMeta.PrimaryKeys 时是自增长字段或者 TableIdColumnName
+func (meta *TableMeta[T]) keyColumns() []string {
+	if len(meta.PrimaryKeys) > 0 {
+		return meta.PrimaryKeys
+	}
+	return []string{meta.idColumn()}
+}
+
+// keyMatcher 根据主键值构建 key1 = ? and key2 = ? 条件，keyValues 的顺序与 TableMeta.PrimaryKeys 相同
+func keyMatcher[T any](meta *TableMeta[T], keyValues []any) (Matcher, error) {
+	columns := meta.keyColumns()
+	if len(keyValues) != len(columns) {
+		return nil, fmt.Errorf("%s: primary key has %d columns, but %d values are given", meta.Table, len(columns), len(keyValues))
+	}
+	m := NewMatcher()
+	for i, column := range columns {
+		m.Eq(column, keyValues[i])
+	}
+	return m, nil
+}
+
+// keysMatcher 根据多个主键值构建 in 条件，单一主键使用 key in (?,?)，联合主键使用 (k1,k2) in ((?,?),(?,?))
+func keysMatcher[T any](meta *TableMeta[T], keys [][]any) (Matcher, error) {
+	columns := meta.keyColumns()
+	if len(columns) > 1 {
+		return NewMatcher().TupleIn(columns, keys), nil
+	}
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		if len(key) != 1 {
+			return nil, fmt.Errorf("%s: primary key has 1 column, but %d values are given", meta.Table, len(key))
+		}
+		values[i] = key[0]
+	}
+	return NewMatcher().In(columns[0], values), nil
+}
+
+// keysChunkSize 返回按主键分批时每批的主键个数，在 MaxInSize 的基础上保证每批的占位符个数不超过 mysql 的限制
+func keysChunkSize[T any](meta *TableMeta[T]) int {
+	size := maxPlaceholders / len(meta.keyColumns())
+	if MaxInSize > 0 && MaxInSize < size {
+		size = MaxInSize
+	}
+	return size
+}
+
+// insKeyValues 读取对象的主键值
+func insKeyValues[T any](meta *TableMeta[T], ins *T) []any {
+	columns := meta.keyColumns()
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		values[i] = meta.LookupFieldFunc(column, ins, false)
+	}
+	return values
+}
+
+// GetByKey 根据主键返回单条数据，支持联合主键以及非 int64 类型的主键，keyValues 的顺序与 TableMeta.PrimaryKeys 相同，
+// 没有设置 TableMeta.PrimaryKeys 时主键是自增长字段或者 TableIdColumnName
+func GetByKey[T any](tc *TransContext, meta *TableMeta[T], keyValues ...any) (*T, error) {
+	m, err := keyMatcher(meta, keyValues)
+	if err != nil {
+		return nil, err
+	}
+	return QueryOneMatcher(tc, m, meta)
+}
+
+// GetByKeys 根据多个主键返回多条数据，每个元素是一个主键的值，顺序与 TableMeta.PrimaryKeys 相同，联合主键使用 (k1,k2) in ((?,?),(?,?)) 查询，
+// 主键个数超过 MaxInSize 或者占位符个数超过 mysql 的限制时分批查询
+func GetByKeys[T any](tc *TransContext, meta *TableMeta[T], keys [][]any, viewColumns ...string) ([]*T, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var ret []*T
+	for _, chunk := range splitInChunks(keys, keysChunkSize(meta)) {
+		m, err := keysMatcher(meta, chunk)
+		if err != nil {
+			return nil, err
+		}
+		list, err := QueryListMatcherWithViewColumns(tc, m, meta, viewColumns)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, list...)
+	}
+	return ret, nil
+}
+
+// UpdateByKey 根据主键修改一条记录，需要修改的字段值通过 Modifier 指定，keyValues 的顺序与 TableMeta.PrimaryKeys 相同
+func UpdateByKey[T any](tc *TransContext, meta *TableMeta[T], modifier Modifier, keyValues ...any) (int64, error) {
+	m, err := keyMatcher(meta, keyValues)
+	if err != nil {
+		return 0, err
+	}
+	return UpdateByModifier(tc, modifier, m, meta)
+}
+
+// DeleteByKey 根据主键删除一条记录，keyValues 的顺序与 TableMeta.PrimaryKeys 相同
+func DeleteByKey[T any](tc *TransContext, meta *TableMeta[T], keyValues ...any) (int64, error) {
+	m, err := keyMatcher(meta, keyValues)
+	if err != nil {
+		return 0, err
+	}
+	return DeleteByMatcher(tc, m, meta)
+}
+
+// DeleteByKeys 根据多个主键删除多条记录，主键个数超过 MaxInSize 或者占位符个数超过 mysql 的限制时在同一个 tc 内分批删除，
+// 某一批失败时的返回值见 MaxInSize
+func DeleteByKeys[T any](tc *TransContext, meta *TableMeta[T], keys [][]any) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return execInChunks(tc, keys, keysChunkSize(meta), func(chunk [][]any) (int64, error) {
+		m, err := keysMatcher(meta, chunk)
+		if err != nil {
+			return 0, err
+		}
+		return DeleteByMatcher(tc, m, meta)
+	})
+}
@@ -0,0 +1,36 @@
+package daog
+
+import "testing"
+
+func TestCompositeKeyColumns(t *testing.T) {
+	meta := *filterSampleMeta
+	meta.PrimaryKeys = []string{"status", "name"}
+
+	orders := cursorOrders(&meta, []*Order{NewDescOrder("name")})
+	if len(orders) != 2 || orders[0].ColumnName != "name" || !orders[0].Desc || orders[1].ColumnName != "status" || orders[1].Desc {
+		t.Error(orders)
+	}
+	if orders = cursorOrders(filterSampleMeta, nil); len(orders) != 1 || orders[0].ColumnName != "id" {
+		t.Error(orders)
+	}
+
+	old := MaxInSize
+	defer func() {
+		MaxInSize = old
+	}()
+	MaxInSize = 0
+	if size := keysChunkSize(&meta); size != maxPlaceholders/2 {
+		t.Error(size)
+	}
+	MaxInSize = 100
+	if size := keysChunkSize(&meta); size != 100 {
+		t.Error(size)
+	}
+
+	err := ScanByPrimaryKeyChunks(nil, &meta, nil, 10, func(tc *TransContext, chunk []*filterSample) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("chunk scan should reject composite primary key")
+	}
+}
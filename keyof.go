@@ -0,0 +1,131 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import "errors"
+
+// singleKeyColumn 返回单一主键的字段名，联合主键时返回错误，联合主键请使用 GetByKey 等函数
+func singleKeyColumn[T any](meta *TableMeta[T]) (string, error) {
+	columns := meta.keyColumns()
+	if len(columns) != 1 {
+		return "", errors.New(meta.Table + ": composite primary key, use GetByKey instead")
+	}
+	return columns[0], nil
+}
+
+// GetByIdOf 与 GetById 类似，但主键类型 K 是泛型，支持 string(char(36)/varchar)、ttypes.UUID(binary(16)) 等非 int64 主键，
+// 主键字段由 TableMeta.PrimaryKeys 确定
+func GetByIdOf[T any, K comparable](tc *TransContext, id K, meta *TableMeta[T], viewColumns ...string) (*T, error) {
+	column, err := singleKeyColumn(meta)
+	if err != nil {
+		return nil, err
+	}
+	return QueryOneMatcher(tc, NewMatcher().Eq(column, id), meta, viewColumns...)
+}
+
+// GetByIdOfForUpdate 与 GetByIdOf 类似， 只是支持 for update
+// skipLocked, true 需要 SKIP LOCKED
+func GetByIdOfForUpdate[T any, K comparable](tc *TransContext, id K, meta *TableMeta[T], skipLocked bool, viewColumns ...string) (*T, error) {
+	column, err := singleKeyColumn(meta)
+	if err != nil {
+		return nil, err
+	}
+	return QueryOneMatcherForUpdate(tc, NewMatcher().Eq(column, id), meta, skipLocked, viewColumns...)
+}
+
+// GetByIdsOf 与 GetByIds 类似，但主键类型 K 是泛型，主键个数超过 MaxInSize 时，会分批查询并合并结果
+func GetByIdsOf[T any, K comparable](tc *TransContext, ids []K, meta *TableMeta[T], viewColumns ...string) ([]*T, error) {
+	column, err := singleKeyColumn(meta)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	var ret []*T
+	for _, chunk := range splitInChunks(ids, MaxInSize) {
+		list, err := QueryListMatcherWithViewColumns(tc, NewMatcher().In(column, ConvertToAnySlice(chunk)), meta, viewColumns)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, list...)
+	}
+	return ret, nil
+}
+
+// UpdateByIdOf 与 UpdateById 类似，但主键类型 K 是泛型
+func UpdateByIdOf[T any, K comparable](tc *TransContext, modifier Modifier, id K, meta *TableMeta[T]) (int64, error) {
+	column, err := singleKeyColumn(meta)
+	if err != nil {
+		return 0, err
+	}
+	return UpdateByModifier(tc, modifier, NewMatcher().Eq(column, id), meta)
+}
+
+// DeleteByIdOf 与 DeleteById 类似，但主键类型 K 是泛型
+func DeleteByIdOf[T any, K comparable](tc *TransContext, id K, meta *TableMeta[T]) (int64, error) {
+	column, err := singleKeyColumn(meta)
+	if err != nil {
+		return 0, err
+	}
+	return DeleteByMatcher(tc, NewMatcher().Eq(column, id), meta)
+}
+
+// DeleteByIdsOf 与 DeleteByIds 类似，但主键类型 K 是泛型，主键个数超过 MaxInSize 时，会在同一个 tc 内分批执行多条 delete 语句
+func DeleteByIdsOf[T any, K comparable](tc *TransContext, ids []K, meta *TableMeta[T]) (int64, error) {
+	column, err := singleKeyColumn(meta)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return execInChunks(tc, ids, MaxInSize, func(chunk []K) (int64, error) {
+		return DeleteByMatcher(tc, NewMatcher().In(column, ConvertToAnySlice(chunk)), meta)
+	})
+}
+
+// KeyDao 主键类型为 K 的表的常用操作，与 QuickDao 配合使用，QuickDao 的 id 相关函数只支持 int64 主键。
+// 按照 ids 顺序返回结果的 GetByIdsInOrder 仍然只支持整数主键，KeyDao 没有对应的方法
+type KeyDao[T any, K comparable] interface {
+	// GetById 封装 GetByIdOf 函数
+	GetById(tc *TransContext, id K, viewColumns ...string) (*T, error)
+	// GetByIdForUpdate 封装 GetByIdOfForUpdate 函数
+	GetByIdForUpdate(tc *TransContext, id K, skipLocked bool, viewColumns ...string) (*T, error)
+	// GetByIds 封装 GetByIdsOf 函数
+	GetByIds(tc *TransContext, ids []K, viewColumns ...string) ([]*T, error)
+	// UpdateById 封装 UpdateByIdOf 函数
+	UpdateById(tc *TransContext, modifier Modifier, id K) (int64, error)
+	// DeleteById 封装 DeleteByIdOf 函数
+	DeleteById(tc *TransContext, id K) (int64, error)
+	// DeleteByIds 封装 DeleteByIdsOf 函数
+	DeleteByIds(tc *TransContext, ids []K) (int64, error)
+}
+
+// NewKeyDao 创建主键类型为 K 的 KeyDao
+func NewKeyDao[T any, K comparable](meta *TableMeta[T]) KeyDao[T, K] {
+	return &baseKeyDao[T, K]{meta}
+}
+
+type baseKeyDao[T any, K comparable] struct {
+	meta *TableMeta[T]
+}
+
+func (dao *baseKeyDao[T, K]) GetById(tc *TransContext, id K, viewColumns ...string) (*T, error) {
+	return GetByIdOf(tc, id, dao.meta, viewColumns...)
+}
+
+func (dao *baseKeyDao[T, K]) GetByIdForUpdate(tc *TransContext, id K, skipLocked bool, viewColumns ...string) (*T, error) {
+	return GetByIdOfForUpdate(tc, id, dao.meta, skipLocked, viewColumns...)
+}
+
+func (dao *baseKeyDao[T, K]) GetByIds(tc *TransContext, ids []K, viewColumns ...string) ([]*T, error) {
+	return GetByIdsOf(tc, ids, dao.meta, viewColumns...)
+}
+
+func (dao *baseKeyDao[T, K]) UpdateById(tc *TransContext, modifier Modifier, id K) (int64, error) {
+	return UpdateByIdOf(tc, modifier, id, dao.meta)
+}
+
+func (dao *baseKeyDao[T, K]) DeleteById(tc *TransContext, id K) (int64, error) {
+	return DeleteByIdOf(tc, id, dao.meta)
+}
+
+func (dao *baseKeyDao[T, K]) DeleteByIds(tc *TransContext, ids []K) (int64, error) {
+	return DeleteByIdsOf(tc, ids, dao.meta)
+}
@@ -4,7 +4,12 @@
 
 package daog
 
-import "github.com/rolandhe/daog/ttypes"
+import (
+	"fmt"
+	"math"
+
+	"github.com/rolandhe/daog/ttypes"
+)
 
 // TableMeta daog中需要表的元数据，基于元数据来自动生成sql，把从数据库读取的数据分配给表的实体对象，TableMeta对应的实例会由compile工具生成。
 // TableMeta 需要知道表名，表的列名，自增长字段名称，以及需要提供一个函数LookupFieldFunc，该函数负责根据表的字段名称找到该名称对应的属性。
@@ -17,8 +22,13 @@ type TableMeta[T any] struct {
 	Table        string
 	Columns      []string
 	// 自增长字段的名称，在insert时，表实体对象中对应的field会被自动填充
-	AutoColumn   string
+	AutoColumn string
+	// 主键字段，联合主键时按照 primary key(...) 中的顺序排列，该字段不能被compile自动生成，需要使用者在compile生成的xx-ext.go中设置，
+	// 为空时主键是 AutoColumn 或者 TableIdColumnName，GetByKey、UpdateByKey、DeleteByKey 以及 Update 使用它构建条件
+	PrimaryKeys  []string
 	StampColumns map[string]int
+	// 表的二级缓存，缺省为nil，表示不缓存，参见 Cache，该字段不能被compile自动生成，需要使用者在compile生成的xx-ext.go中设置
+	Cache Cache
 }
 
 // ExtractFieldValues 从给定的T对象中抽取属性值，并返回，抽取的属性值可能是属性指针，也可能是属性的值，
@@ -36,6 +46,74 @@ func (meta *TableMeta[T]) ExtractFieldValues(ins *T, point bool, exclude map[str
 	return ret
 }
 
+// resolveViewColumns 返回视图最终需要查询的表字段，view 为 nil 或者没有指定字段时返回所有字段
+func (meta *TableMeta[T]) resolveViewColumns(view *View) []string {
+	if view == nil || len(view.viewColumns) == 0 {
+		return meta.Columns
+	}
+	if view.include {
+		return view.viewColumns
+	}
+	var excludeMap = make(map[string]int)
+	for _, c := range view.viewColumns {
+		excludeMap[c] = 1
+	}
+	var includeColumns []string
+	for _, c := range meta.Columns {
+		if excludeMap[c] == 0 {
+			includeColumns = append(includeColumns, c)
+		}
+	}
+	return includeColumns
+}
+
+// idColumn 返回 GetById、DeleteById 等 id 函数使用的主键字段名，PrimaryKeys 只有一个字段时是该字段，与 Update、GetByKey 等保持一致，
+// 否则有自增长字段时是自增长字段，再否则是 TableIdColumnName
+func (meta *TableMeta[T]) idColumn() string {
+	if len(meta.PrimaryKeys) == 1 {
+		return meta.PrimaryKeys[0]
+	}
+	if meta.AutoColumn != "" {
+		return meta.AutoColumn
+	}
+	return TableIdColumnName
+}
+
+// idOfIns 读取对象的主键值，主键必须是整数类型，否则返回错误，超出 int64 范围的无符号主键同样返回错误
+func idOfIns[T any](meta *TableMeta[T], ins *T) (int64, error) {
+	switch v := meta.LookupFieldFunc(meta.idColumn(), ins, false).(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint:
+		return uint64ToId(meta.Table, uint64(v))
+	case uint64:
+		return uint64ToId(meta.Table, v)
+	default:
+		return 0, fmt.Errorf("%s: primary key of type %T is not an integer", meta.Table, v)
+	}
+}
+
+func uint64ToId(table string, v uint64) (int64, error) {
+	if v > math.MaxInt64 {
+		return 0, fmt.Errorf("%s: primary key %d overflows int64", table, v)
+	}
+	return int64(v), nil
+}
+
 func (meta *TableMeta[T]) shouldExcludeColumns(ins *T, isUpdate bool) map[string]int {
 	exclude := make(map[string]int)
 	if meta.AutoColumn != "" {
@@ -0,0 +1,63 @@
+package daog
+
+import (
+	"math"
+	"testing"
+)
+
+type uintIdSample struct {
+	Id uint64
+}
+
+func TestIdOfIns(t *testing.T) {
+	meta := &TableMeta[uintIdSample]{
+		Table: "uint_id_sample",
+		LookupFieldFunc: func(columnName string, ins *uintIdSample, point bool) any {
+			if columnName != "id" {
+				return nil
+			}
+			if point {
+				return &ins.Id
+			}
+			return ins.Id
+		},
+	}
+	if id, err := idOfIns(meta, &uintIdSample{Id: math.MaxInt64}); err != nil || id != math.MaxInt64 {
+		t.Error(id, err)
+	}
+	if _, err := idOfIns(meta, &uintIdSample{Id: math.MaxInt64 + 1}); err == nil {
+		t.Error("uint64 id greater than MaxInt64 should fail")
+	}
+	if id, err := idOfIns(filterSampleMeta, &filterSample{Id: -3}); err != nil || id != -3 {
+		t.Error(id, err)
+	}
+}
+
+func TestIdColumnFollowsSinglePrimaryKey(t *testing.T) {
+	meta := *filterSampleMeta
+	if meta.idColumn() != "id" {
+		t.Error(meta.idColumn())
+	}
+	meta.AutoColumn = "id"
+	meta.PrimaryKeys = []string{"status"}
+	if meta.idColumn() != "status" {
+		t.Error(meta.idColumn())
+	}
+	meta.PrimaryKeys = []string{"status", "name"}
+	if meta.idColumn() != "id" {
+		t.Error("composite primary key should fall back to AutoColumn", meta.idColumn())
+	}
+
+	meta.PrimaryKeys = []string{"status"}
+	db := &fakeDB{}
+	tc := newFakeTc(t, db)
+	if _, err := GetById(tc, 3, &meta); err != nil || db.lastQuery() != "select id,name,status,create_at from filter_sample where status = ? limit 1" {
+		t.Error(err, db.lastQuery())
+	}
+	if _, err := DeleteById(tc, 3, &meta); err != nil || db.lastQuery() != "delete from filter_sample where status = ?" {
+		t.Error(err, db.lastQuery())
+	}
+	if _, err := UpdateById(tc, NewModifier().Add("name", "a"), 3, &meta); err != nil || db.lastQuery() != "update filter_sample set name=? where status = ?" {
+		t.Error(err, db.lastQuery())
+	}
+}
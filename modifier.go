@@ -39,6 +39,7 @@ type Modifier interface {
 	SelfMinus(column string, value any) Modifier
 	toSQL(tableName string) (string, []any)
 	getPureChangePairs() ([]string, []any)
+	columns() []string
 }
 
 type internalModifier struct {
@@ -105,6 +106,14 @@ func (m *internalModifier) toSQL(tableName string) (string, []any) {
 	return "update " + tableName + " set " + strings.Join(modStmt, ","), args
 }
 
+func (m *internalModifier) columns() []string {
+	columns := make([]string, len(m.modifies))
+	for i, p := range m.modifies {
+		columns[i] = p.column
+	}
+	return columns
+}
+
 func (m *internalModifier) getPureChangePairs() ([]string, []any) {
 	var columns []string
 	var values []any
@@ -0,0 +1,177 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamedParamTag 使用 struct 绑定命名参数时，读取参数名的 field tag，没有 tag 时使用 field 名称的下划线形式，比如 CreateAt 对应 :create_at，
+// tag 为 "-" 的 field 被忽略
+const NamedParamTag = "daog"
+
+// BindNamed 把含有 :name 命名参数的 sql 转换成使用 ? 占位符的 sql 及对应的参数，字符串常量、`标识符`及注释中的 :name 以及 := 不作为命名参数。
+// params 可以是 map[string]any 或者 struct(及其指针)，同一个命名参数可以出现多次。
+// 绑定到 slice(除了 []byte)的命名参数会被展开成 ?,?,? 的形式，用于 in 条件，比如 id in (:ids)，slice 不能为空
+func BindNamed(sql string, params any) (string, []any, error) {
+	lookup, err := namedParamsLookup(params)
+	if err != nil {
+		return "", nil, err
+	}
+	var builder strings.Builder
+	var args []any
+	scanSQLTokens(sql, matchNamedParam, func(seg string, placeholder bool) bool {
+		if !placeholder {
+			builder.WriteString(seg)
+			return true
+		}
+		name := seg[1:]
+		value, ok := lookup(name)
+		if !ok {
+			err = errors.New("named param :" + name + " is not bound")
+			return false
+		}
+		values, expand := expandNamedValue(value)
+		if !expand {
+			builder.WriteByte('?')
+			args = append(args, value)
+			return true
+		}
+		if len(values) == 0 {
+			err = errors.New("named param :" + name + " is an empty slice")
+			return false
+		}
+		builder.WriteString(strings.Repeat("?,", len(values)-1) + "?")
+		args = append(args, values...)
+		return true
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return builder.String(), args, nil
+}
+
+// QueryNamedSQL 与 QueryRawSQL 类似，但 sql 使用 :name 命名参数，参数从 params 中绑定，规则见 BindNamed
+func QueryNamedSQL[T any](tc *TransContext, extract ExtractScanFieldPoints[T], sql string, params any) ([]*T, error) {
+	stmt, args, err := BindNamed(sql, params)
+	if err != nil {
+		return nil, err
+	}
+	return QueryRawSQL(tc, extract, stmt, args...)
+}
+
+// ExecNamedSQL 与 ExecRawSQL 类似，但 sql 使用 :name 命名参数，参数从 params 中绑定，规则见 BindNamed
+func ExecNamedSQL(tc *TransContext, sql string, params any) (int64, error) {
+	stmt, args, err := BindNamed(sql, params)
+	if err != nil {
+		return 0, err
+	}
+	return ExecRawSQL(tc, stmt, args...)
+}
+
+// matchNamedParam 识别 :name 形式的命名参数，:= 以及 :: 不是命名参数
+func matchNamedParam(sql string, i int) int {
+	if sql[i] != ':' || (i > 0 && sql[i-1] == ':') {
+		return 0
+	}
+	n := 1
+	for i+n < len(sql) && isNamedParamChar(sql[i+n]) {
+		n++
+	}
+	if n == 1 {
+		return 0
+	}
+	return n
+}
+
+func isNamedParamChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func namedParamsLookup(params any) (func(name string) (any, bool), error) {
+	if m, ok := params.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+	rv := reflect.ValueOf(params)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("named params must be map[string]any or struct")
+	}
+	fields := map[string]reflect.Value{}
+	collectNamedFields(rv, fields)
+	return func(name string) (any, bool) {
+		v, ok := fields[name]
+		if !ok {
+			return nil, false
+		}
+		return v.Interface(), true
+	}, nil
+}
+
+// collectNamedFields 收集 struct 的导出 field，匿名嵌入的 struct 会被展开
+func collectNamedFields(rv reflect.Value, fields map[string]reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get(NamedParamTag)
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			collectNamedFields(rv.Field(i), fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = toSnakeCase(field.Name)
+		}
+		fields[name] = rv.Field(i)
+	}
+}
+
+// expandNamedValue 判断参数是否需要展开成 in 列表，[]byte 及实现了 driver.Valuer 的类型不展开
+func expandNamedValue(value any) ([]any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+func toSnakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
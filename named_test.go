@@ -0,0 +1,49 @@
+package daog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBindNamedMap(t *testing.T) {
+	sql, args, err := BindNamed("select * from t where a = :a and b in (:ids) and c = ':a' -- :a\n and d = :a and @v := 1",
+		map[string]any{"a": 1, "ids": []int64{3, 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "select * from t where a = ? and b in (?,?) and c = ':a' -- :a\n and d = ? and @v := 1" {
+		t.Error(sql)
+	}
+	if !reflect.DeepEqual(args, []any{1, int64(3), int64(4), 1}) {
+		t.Error(args)
+	}
+
+	if _, _, err = BindNamed("select * from t where a = :missing", map[string]any{}); err == nil {
+		t.Error("unbound param should fail")
+	}
+	if _, _, err = BindNamed("select * from t where a in (:ids)", map[string]any{"ids": []int{}}); err == nil {
+		t.Error("empty slice should fail")
+	}
+}
+
+func TestBindNamedStruct(t *testing.T) {
+	type base struct {
+		TenantId int64
+	}
+	params := &struct {
+		base
+		UserName string
+		Data     []byte
+		Status   int32 `daog:"st"`
+	}{base{7}, "tom", []byte("x"), 1}
+	sql, args, err := BindNamed("update t set data = :data where tenant_id = :tenant_id and user_name = :user_name and status = :st", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "update t set data = ? where tenant_id = ? and user_name = ? and status = ?" {
+		t.Error(sql)
+	}
+	if !reflect.DeepEqual(args, []any{[]byte("x"), int64(7), "tom", int32(1)}) {
+		t.Error(args)
+	}
+}
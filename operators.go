@@ -8,6 +8,7 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/json"
+	txrequest "github.com/rolandhe/daog/tx"
 	"github.com/rolandhe/daog/utils"
 	"strconv"
 	"strings"
@@ -31,6 +32,39 @@ func ConvertToAnySlice[T any](data []T) []any {
 	return target
 }
 
+// splitInChunks 把 values 按照 size 拆分成多段，size <= 0 时不拆分
+func splitInChunks[E any](values []E, size int) [][]E {
+	if size <= 0 || len(values) <= size {
+		return [][]E{values}
+	}
+	chunks := make([][]E, 0, (len(values)+size-1)/size)
+	for start := 0; start < len(values); start += size {
+		end := start + size
+		if end > len(values) {
+			end = len(values)
+		}
+		chunks = append(chunks, values[start:end])
+	}
+	return chunks
+}
+
+// execInChunks 把 values 按照 chunkSize 分批，在同一个 tc 内对每一批调用 exec 执行一条语句，返回各批影响记录数之和，
+// 某一批失败时的返回值见 MaxInSize 的说明
+func execInChunks[E any](tc *TransContext, values []E, chunkSize int, exec func(chunk []E) (int64, error)) (int64, error) {
+	var affectRow int64
+	for _, chunk := range splitInChunks(values, chunkSize) {
+		n, err := exec(chunk)
+		if err != nil {
+			if tc.txRequest == txrequest.RequestNone {
+				return affectRow, err
+			}
+			return 0, err
+		}
+		affectRow += n
+	}
+	return affectRow, nil
+}
+
 // GetTableName  根据meta及上下文中的信息来确定表名称，这应用于分表的场景，记住这需要支持表shard的事务上下文
 func GetTableName[T any](ctx context.Context, meta *TableMeta[T]) string {
 	tableName := meta.Table
@@ -43,33 +77,49 @@ func GetTableName[T any](ctx context.Context, meta *TableMeta[T]) string {
 }
 
 func buildSelectBase[T any](meta *TableMeta[T], view *View, ctx context.Context) string {
-	columnsStr := ""
-	if view == nil || len(view.viewColumns) == 0 {
-		columnsStr = strings.Join(meta.Columns, ",")
-	} else {
-		var includeColumns []string
-		if view.include {
-			includeColumns = view.viewColumns
-		} else {
-			var excludeMap = make(map[string]int)
-			for _, c := range view.viewColumns {
-				excludeMap[c] = 1
-			}
-			for _, c := range meta.Columns {
-				if excludeMap[c] == 0 {
-					includeColumns = append(includeColumns, c)
-				}
-			}
-		}
-		columnsStr = strings.Join(includeColumns, ",")
+	columnsStr := strings.Join(meta.resolveViewColumns(view), ",")
+	if view == nil {
+		return "select " + columnsStr + " from " + GetTableName(ctx, meta)
+	}
+	var builder strings.Builder
+	builder.WriteString("select")
+	if view.Hints != nil {
+		builder.WriteString(view.Hints.optimizerComment())
+	}
+	if view.Distinct {
+		builder.WriteString(" distinct")
+	}
+	if view.Hints != nil {
+		builder.WriteString(view.Hints.selectModifiers())
+	}
+	builder.WriteString(" ")
+	builder.WriteString(columnsStr)
+	builder.WriteString(" from ")
+	builder.WriteString(GetTableName(ctx, meta))
+	if view.Hints != nil {
+		builder.WriteString(view.Hints.tableSuffix())
 	}
-	return "select " + columnsStr + " from " + GetTableName(ctx, meta)
+	return builder.String()
 }
 
+// selectQuery 生成 select 语句，matcher 为 nil 或者没有条件时也会拼接 order by 及 limit
 func selectQuery[T any](meta *TableMeta[T], ctx context.Context, matcher Matcher, pager *Pager, orders []*Order, view *View) (string, []any, error) {
+	if err := checkQueryColumns(meta, view, matcher, orders); err != nil {
+		return "", nil, err
+	}
+	if pager != nil {
+		if err := pager.Validate(); err != nil {
+			return "", nil, err
+		}
+	}
+	if view != nil && view.Hints != nil {
+		if err := view.Hints.validate(); err != nil {
+			return "", nil, err
+		}
+	}
 	base := buildSelectBase(meta, view, ctx)
 	if matcher == nil {
-		return base, nil, nil
+		return base + buildQuerySuffix(pager, orders), nil, nil
 	}
 	var args []any
 	condi, args, err := matcher.ToSQL(args)
@@ -84,12 +134,44 @@ func selectQuery[T any](meta *TableMeta[T], ctx context.Context, matcher Matcher
 	return base + " where " + condi + buildQuerySuffix(pager, orders), args, nil
 }
 
-func countQuery[T any](meta *TableMeta[T], ctx context.Context, matcher Matcher) (string, []any, error) {
+func existsQuery[T any](meta *TableMeta[T], ctx context.Context, matcher Matcher, hints *QueryHints) (string, []any, error) {
+	if err := checkMatcherColumns(meta, matcher); err != nil {
+		return "", nil, err
+	}
+	if hints != nil {
+		if err := hints.validate(); err != nil {
+			return "", nil, err
+		}
+	}
+	base := hintedSelectBase(hints, "1", GetTableName(ctx, meta))
+	if matcher == nil {
+		return base + " limit 1", nil, nil
+	}
+	var args []any
+	condi, args, err := matcher.ToSQL(args)
+	if err != nil {
+		return "", nil, err
+	}
+	if condi == "" {
+		return base + " limit 1", nil, nil
+	}
+	return base + " where " + condi + " limit 1", args, nil
+}
+
+func countQuery[T any](meta *TableMeta[T], ctx context.Context, matcher Matcher, hints *QueryHints) (string, []any, error) {
+	if err := checkMatcherColumns(meta, matcher); err != nil {
+		return "", nil, err
+	}
+	if hints != nil {
+		if err := hints.validate(); err != nil {
+			return "", nil, err
+		}
+	}
 	var base string
 	if meta.AutoColumn == "" {
-		base = "select count(*) from " + GetTableName(ctx, meta)
+		base = hintedSelectBase(hints, "count(*)", GetTableName(ctx, meta))
 	} else {
-		base = "select count(" + meta.AutoColumn + ") from " + GetTableName(ctx, meta)
+		base = hintedSelectBase(hints, "count("+meta.AutoColumn+")", GetTableName(ctx, meta))
 	}
 
 	if matcher == nil {
@@ -133,8 +215,7 @@ func buildQuerySuffix(pager *Pager, orders []*Order) string {
 	if pager.PageNumber == 1 {
 		limitStat = " limit " + strconv.Itoa(pager.PageSize)
 	} else {
-		startPos := int64(pager.PageNumber-1) * int64(pager.PageSize)
-		limitStat = " limit " + strconv.FormatInt(startPos, 10) + "," + strconv.Itoa(pager.PageSize)
+		limitStat = " limit " + strconv.FormatInt(pager.offset(), 10) + "," + strconv.Itoa(pager.PageSize)
 	}
 	return ordStat + limitStat
 }
@@ -152,6 +233,9 @@ func buildUpdateBase[T any](meta *TableMeta[T], ctx context.Context, exclude map
 }
 
 func updateExec[T any](meta *TableMeta[T], ins *T, ctx context.Context, matcher Matcher) (string, []any, error) {
+	if err := checkMatcherColumns(meta, matcher); err != nil {
+		return "", nil, err
+	}
 	exclude := meta.shouldExcludeColumns(ins, true)
 	base := buildUpdateBase(meta, ctx, exclude)
 	if matcher == nil {
@@ -171,6 +255,12 @@ func updateExec[T any](meta *TableMeta[T], ins *T, ctx context.Context, matcher
 }
 
 func buildModifierExec[T any](meta *TableMeta[T], ctx context.Context, modifier Modifier, matcher Matcher) (string, []any, error) {
+	if err := checkModifierColumns(meta, modifier); err != nil {
+		return "", nil, err
+	}
+	if err := checkMatcherColumns(meta, matcher); err != nil {
+		return "", nil, err
+	}
 	tableName := GetTableName(ctx, meta)
 	if BeforeModifyCallback != nil {
 		pcColumns, pcValues := modifier.getPureChangePairs()
@@ -219,7 +309,8 @@ func traceLogSQLBefore(ctx context.Context, sql string, args []any) string {
 	var argJson []byte
 	md5data := []byte(sql)
 
-	argJson, err := json.Marshal(args)
+	values := formatArgs(args)
+	argJson, err := json.Marshal(values)
 	if err != nil {
 		GLogger.Error(ctx, err)
 	} else {
@@ -228,7 +319,7 @@ func traceLogSQLBefore(ctx context.Context, sql string, args []any) string {
 	sumData := md5.Sum(md5data)
 	sqlMd5 := utils.ToUpperHexString(sumData[:])
 	outArgJson := argJson
-	smallArgs, changed := shortArgs(args)
+	smallArgs, changed := shortArgs(values)
 	if changed {
 		outArgJson, err = json.Marshal(smallArgs)
 		if err != nil {
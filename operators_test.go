@@ -0,0 +1,31 @@
+package daog
+
+import (
+	"errors"
+	"testing"
+
+	txrequest "github.com/rolandhe/daog/tx"
+)
+
+func TestExecInChunks(t *testing.T) {
+	failAt := errors.New("fail")
+	exec := func(chunk []int) (int64, error) {
+		if chunk[0] >= 5 {
+			return 0, failAt
+		}
+		return int64(len(chunk)), nil
+	}
+	values := []int{1, 2, 3, 4, 5}
+
+	tc := &TransContext{txRequest: txrequest.RequestNone}
+	if n, err := execInChunks(tc, values[:3], 2, exec); n != 3 || err != nil {
+		t.Error(n, err)
+	}
+	if n, err := execInChunks(tc, values, 2, exec); n != 4 || err != failAt {
+		t.Error("auto commit should return rows of finished chunks", n, err)
+	}
+	tc.txRequest = txrequest.RequestWrite
+	if n, err := execInChunks(tc, values, 2, exec); n != 0 || err != failAt {
+		t.Error("transaction should return 0", n, err)
+	}
+}
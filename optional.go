@@ -0,0 +1,94 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+	"reflect"
+	"time"
+)
+
+// EmptyPredicate 判断条件值是否为空的函数，用于可选条件，值为空时对应的条件会被忽略
+type EmptyPredicate func(value any) bool
+
+// DefaultEmptyPredicate NewOptionalMatcher / NewOptionalOrMatcher 创建的 Matcher 缺省使用的判空函数，可以替换成您自己的实现
+var DefaultEmptyPredicate EmptyPredicate = IsEmptyValue
+
+// IsEmptyValue 缺省的判空函数，以下情况认为值为空:
+//
+//	nil、nil 指针、零值(比如 0, "", false, 零时间)、长度为0的 slice 或者 map
+//
+//	Valid 为 false 的 ttypes.NilableString、ttypes.NilableDatetime、ttypes.NilableDate
+//
+// 注意，非 nil 的指针不会被认为是空，即使它指向零值，这可以用来表达"明确要求按零值查询"的语义
+func IsEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	case int8:
+		return v == 0
+	case int16:
+		return v == 0
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case uint:
+		return v == 0
+	case uint8:
+		return v == 0
+	case uint16:
+		return v == 0
+	case uint32:
+		return v == 0
+	case uint64:
+		return v == 0
+	case float32:
+		return v == 0
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []any:
+		return len(v) == 0
+	case [][]any:
+		return len(v) == 0
+	case []byte:
+		return len(v) == 0
+	case time.Time:
+		return v.IsZero()
+	case decimal.Decimal:
+		return v.IsZero()
+	case ttypes.NormalDatetime:
+		return v.ToTimePointer().IsZero()
+	case ttypes.NormalDate:
+		return v.ToTimePointer().IsZero()
+	case ttypes.NilableString:
+		return !v.Valid
+	case *ttypes.NilableString:
+		return v == nil || !v.Valid
+	case ttypes.NilableDatetime:
+		return !v.Valid
+	case *ttypes.NilableDatetime:
+		return v == nil || !v.Valid
+	case ttypes.NilableDate:
+		return !v.Valid
+	case *ttypes.NilableDate:
+		return v == nil || !v.Valid
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
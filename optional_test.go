@@ -0,0 +1,86 @@
+package daog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+)
+
+func TestIsEmptyValue(t *testing.T) {
+	var nilIntPointer *int
+	zero := 0
+	var uuid ttypes.UUID
+	cases := []struct {
+		value any
+		empty bool
+	}{
+		{nil, true},
+		{"", true},
+		{"a", false},
+		{0, true},
+		{int8(1), false},
+		{uint64(0), true},
+		{0.0, true},
+		{false, true},
+		{true, false},
+		{time.Time{}, true},
+		{time.Now(), false},
+		{nilIntPointer, true},
+		{&zero, false},
+		{[]any{}, true},
+		{[]int{}, true},
+		{[]int{1}, false},
+		{[]byte{}, true},
+		{[][]any{}, true},
+		{map[string]int{}, true},
+		{decimal.Zero, true},
+		{decimal.NewFromInt(1), false},
+		{ttypes.NormalDatetime{}, true},
+		{ttypes.NormalDatetime(time.Now()), false},
+		{ttypes.NormalDate{}, true},
+		{ttypes.NilableString{}, true},
+		{*ttypes.FromString(""), false},
+		{(*ttypes.NilableString)(nil), true},
+		{ttypes.NilableDatetime{}, true},
+		{ttypes.FromDatetime(time.Now()), false},
+		{ttypes.NilableDate{}, true},
+		{(*ttypes.NilableDate)(nil), true},
+		{uuid, true},
+		{ttypes.UUID{1}, false},
+	}
+	for i, c := range cases {
+		if got := IsEmptyValue(c.value); got != c.empty {
+			t.Errorf("case %d %T %v: expect %v", i, c.value, c.value, c.empty)
+		}
+	}
+}
+
+func TestOptionalBy(t *testing.T) {
+	negative := func(v any) bool {
+		return v.(int) < 0
+	}
+	cases := []struct {
+		matcher Matcher
+		sql     string
+		args    []any
+	}{
+		{NewOptionalMatcher().Eq("name", "").Eq("status", 0), "", nil},
+		{NewOptionalMatcher().OptionalBy(negative).Eq("status", 0).Eq("name", ""), "status = ?", []any{0}},
+		{NewOptionalMatcher().OptionalBy(negative).Eq("status", -1).Eq("id", 0), "", nil},
+		{NewMatcher().OptionalBy(negative).Eq("status", -1).Eq("id", 0), "id = ?", []any{0}},
+		{NewMatcher().OptionalBy(negative).Eq("status", 1).Eq("id", -1), "status = ? and id = ?", []any{1, -1}},
+		{NewMatcher().OptionalBy(negative).Null("name", false).Eq("id", -1), "name is null and id = ?", []any{-1}},
+		{NewOptionalMatcher().Between("id", 0, 10), "id <= ?", []any{10}},
+		{NewOptionalMatcher().Between("id", 0, 0), "", nil},
+		{NewMatcher().OptionalBy(negative).Between("id", -1, 10).Between("id", -1, 10), "id <= ? and id between ? and ?", []any{10, -1, 10}},
+	}
+	for i, c := range cases {
+		sql, args, err := c.matcher.ToSQL(nil)
+		if err != nil || sql != c.sql || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("case %d: %s %v %v", i, sql, args, err)
+		}
+	}
+}
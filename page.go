@@ -0,0 +1,69 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+// PageResult 分页查询的结果，包含当前页的数据以及总数、总页数
+type PageResult[T any] struct {
+	Items      []*T
+	Total      int64
+	PageSize   int
+	PageNumber int
+	TotalPages int64
+}
+
+// PageQueryOptions 分页查询的选项
+type PageQueryOptions struct {
+	// SkipCountWhenNotFull 为 true 时，如果查询到的当前页数据不满一页且不为空，说明已经是最后一页，直接根据页码计算总数，不再执行 count 查询
+	SkipCountWhenNotFull bool
+}
+
+// QueryPage 根据查询条件 Matcher 及 Pager 查询一页数据，同时使用同一个 Matcher 执行 count 查询，返回包含总数及总页数的 PageResult
+// pager 不能为nil，PageSize 和 PageNumber 都必须大于0
+func QueryPage[T any](tc *TransContext, m Matcher, meta *TableMeta[T], pager *Pager, orders ...*Order) (*PageResult[T], error) {
+	return QueryPageWithOptions(tc, m, meta, nil, pager, nil, orders...)
+}
+
+// QueryPageWithOptions 与 QueryPage 类似，view 指定需要查询的视图，可以为 nil，options 指定分页查询选项，可以为 nil
+func QueryPageWithOptions[T any](tc *TransContext, m Matcher, meta *TableMeta[T], view *View, pager *Pager, options *PageQueryOptions, orders ...*Order) (*PageResult[T], error) {
+	if pager == nil {
+		return nil, ErrInvalidPager
+	}
+	if err := pager.Validate(); err != nil {
+		return nil, err
+	}
+	items, err := QueryPageListMatcherWithViewObj(tc, m, meta, view, pager, orders...)
+	if err != nil {
+		return nil, err
+	}
+	total, known := totalWithoutCount(pager, options, len(items))
+	if !known {
+		var hints *QueryHints
+		if view != nil {
+			hints = view.Hints
+		}
+		total, err = CountWithHints(tc, m, meta, hints)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &PageResult[T]{
+		Items:      items,
+		Total:      total,
+		PageSize:   pager.PageSize,
+		PageNumber: pager.PageNumber,
+		TotalPages: totalPages(total, pager.PageSize),
+	}, nil
+}
+
+// totalWithoutCount 在 SkipCountWhenNotFull 生效时根据页码及当前页的数据条数计算总数，第二个返回值为 false 表示需要执行 count 查询
+func totalWithoutCount(pager *Pager, options *PageQueryOptions, itemCount int) (int64, bool) {
+	if options == nil || !options.SkipCountWhenNotFull || itemCount == 0 || itemCount >= pager.PageSize {
+		return 0, false
+	}
+	return pager.offset() + int64(itemCount), true
+}
+
+func totalPages(total int64, pageSize int) int64 {
+	return (total + int64(pageSize) - 1) / int64(pageSize)
+}
@@ -0,0 +1,64 @@
+package daog
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestPagerValidate(t *testing.T) {
+	for _, pager := range []*Pager{NewPager(0, 1), NewPager(10, 0), NewPager(-1, 2)} {
+		if err := pager.Validate(); err != ErrInvalidPager {
+			t.Error(pager, err)
+		}
+	}
+	pager := NewPager(10, 3)
+	if err := pager.Validate(); err != nil || pager.offset() != 20 {
+		t.Error(pager.offset(), err)
+	}
+	if _, _, err := BuildSelect(filterSampleMeta, nil, NewPager(10, 0), nil, nil); err != ErrInvalidPager {
+		t.Error(err)
+	}
+}
+
+func TestBuildSelectNilMatcherSuffix(t *testing.T) {
+	sql, args, err := BuildSelect(filterSampleMeta, nil, NewPager(10, 3), []*Order{NewDescOrder("id")}, NewView([]string{"id"}))
+	if err != nil || args != nil || sql != "select id from filter_sample order by id desc limit 20,10" {
+		t.Error(sql, args, err)
+	}
+}
+
+func TestTotalWithoutCount(t *testing.T) {
+	skip := &PageQueryOptions{SkipCountWhenNotFull: true}
+	pager := NewPager(10, 3)
+	if total, known := totalWithoutCount(pager, skip, 4); !known || total != 24 {
+		t.Error(total, known)
+	}
+	if _, known := totalWithoutCount(pager, skip, 10); known {
+		t.Error("full page needs count")
+	}
+	if _, known := totalWithoutCount(pager, skip, 0); known {
+		t.Error("empty page needs count")
+	}
+	if _, known := totalWithoutCount(pager, nil, 4); known {
+		t.Error("count is skipped only when enabled")
+	}
+	if totalPages(24, 10) != 3 || totalPages(20, 10) != 2 || totalPages(0, 10) != 0 {
+		t.Error("total pages")
+	}
+}
+
+func TestQueryPageNilMatcher(t *testing.T) {
+	db := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(7)}}}
+	tc := newFakeTc(t, db)
+	ret, err := QueryPageWithOptions(tc, nil, filterSampleMeta, NewView([]string{"id"}), NewPager(10, 2),
+		&PageQueryOptions{SkipCountWhenNotFull: true}, NewDescOrder("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.queries) != 1 || db.lastQuery() != "select id from filter_sample order by id desc limit 10,10" {
+		t.Error(db.queries)
+	}
+	if len(ret.Items) != 1 || ret.Items[0].Id != 7 || ret.Total != 11 || ret.TotalPages != 2 {
+		t.Error(ret)
+	}
+}
@@ -0,0 +1,35 @@
+package daog
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestPluck(t *testing.T) {
+	db := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(3)}, {int64(5)}}}
+	tc := newFakeTc(t, db)
+
+	ids, err := Pluck[int64](tc, filterSampleMeta, "id", NewMatcher().Eq("status", 1), NewDescOrder("id"))
+	if err != nil || !reflect.DeepEqual(ids, []int64{3, 5}) {
+		t.Error(ids, err)
+	}
+	if q := db.lastQuery(); q != "select id from filter_sample where status = ? order by id desc" {
+		t.Error(q)
+	}
+
+	db.columns = []string{"name"}
+	db.rows = [][]driver.Value{{[]byte("tom")}}
+	names, err := PluckDistinct[string](tc, filterSampleMeta, "name", nil, NewOrder("name"))
+	if err != nil || !reflect.DeepEqual(names, []string{"tom"}) {
+		t.Error(names, err)
+	}
+	if q := db.lastQuery(); q != "select distinct name from filter_sample order by name" {
+		t.Error(q)
+	}
+
+	count := len(db.queries)
+	if _, err = PluckDistinct[string](tc, filterSampleMeta, "name", nil, NewOrder("id")); err == nil || len(db.queries) != count {
+		t.Error("distinct order by another column should fail before querying", err)
+	}
+}
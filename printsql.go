@@ -0,0 +1,257 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/rolandhe/daog/utils"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 以下 BuildXxx 函数生成与对应的查询、修改函数完全相同的 sql 及参数，但并不执行，一般用于调试或者输出日志。
+// 它们没有 TransContext，所以表名总是 TableMeta.Table，不会经过 TableMeta.ShardingFunc 计算分表
+
+// BuildWhere 生成 Matcher 对应的 where 条件(不含 where 关键字)及参数
+func BuildWhere(m Matcher) (string, []any, error) {
+	if m == nil {
+		return "", nil, nil
+	}
+	return m.ToSQL(nil)
+}
+
+// BuildSelect 生成 QueryPageListMatcherWithViewObj 执行的 select 语句及参数, pager、orders、view 都可以为 nil
+func BuildSelect[T any](meta *TableMeta[T], m Matcher, pager *Pager, orders []*Order, view *View) (string, []any, error) {
+	return selectQuery(unshardedMeta(meta), context.Background(), m, pager, orders, view)
+}
+
+// BuildCount 生成 Count 执行的 select count 语句及参数
+func BuildCount[T any](meta *TableMeta[T], m Matcher) (string, []any, error) {
+	return countQuery(unshardedMeta(meta), context.Background(), m, nil)
+}
+
+// unshardedMeta 返回去掉了 ShardingFunc 的 meta 浅拷贝，使 GetTableName 直接返回 TableMeta.Table
+func unshardedMeta[T any](meta *TableMeta[T]) *TableMeta[T] {
+	if meta.ShardingFunc == nil {
+		return meta
+	}
+	cp := *meta
+	cp.ShardingFunc = nil
+	return &cp
+}
+
+// BuildUpdate 生成 UpdateByModifier 执行的 update 语句及参数, 但不会回调 BeforeModifyCallback 及 AddNewModifyFieldBeforeUpdate
+func BuildUpdate[T any](meta *TableMeta[T], modifier Modifier, m Matcher) (string, []any, error) {
+	if err := checkModifierColumns(meta, modifier); err != nil {
+		return "", nil, err
+	}
+	if err := checkMatcherColumns(meta, m); err != nil {
+		return "", nil, err
+	}
+	base, args := modifier.toSQL(meta.Table)
+	if base == "" || m == nil {
+		return base, args, nil
+	}
+	condi, args, err := m.ToSQL(args)
+	if err != nil {
+		return "", nil, err
+	}
+	if condi == "" {
+		return base, args, nil
+	}
+	return base + " where " + condi, args, nil
+}
+
+// BuildDelete 生成 DeleteByMatcher 执行的 delete 语句及参数
+func BuildDelete[T any](meta *TableMeta[T], m Matcher) (string, []any, error) {
+	if err := checkMatcherColumns(meta, m); err != nil {
+		return "", nil, err
+	}
+	condi, args, err := BuildWhere(m)
+	if err != nil {
+		return "", nil, err
+	}
+	if condi == "" {
+		return "", nil, errors.New("you can't delete all rows of table error")
+	}
+	return "delete from " + meta.Table + " where " + condi, args, nil
+}
+
+// Interpolate 把参数安全的转义后填充到 sql 的 ? 占位符中，生成一条可以直接在 mysql 客户端执行的语句，用于调试，
+// 参数与 sql 日志中的参数一样先经过 formatArg 转换，字符串中的单引号被转义成两个单引号，含有 \ 或者控制字符的字符串以及 []byte 输出成 X'..' 十六进制形式，
+// 不依赖反斜杠转义，sql_mode 含有 NO_BACKSLASH_ESCAPES 时同样正确，时间按照 2006-01-02 15:04:05.999999 格式输出，不做时区转换。
+// 注意，不要使用该函数生成的sql去执行业务，参数化的sql总是更安全的
+func Interpolate(sql string, args []any) (string, error) {
+	var builder strings.Builder
+	builder.Grow(len(sql) + len(args)*8)
+	argIndex := 0
+	var err error
+	scanPlaceholders(sql, func(seg string, placeholder bool) bool {
+		if !placeholder {
+			builder.WriteString(seg)
+			return true
+		}
+		if argIndex >= len(args) {
+			err = fmt.Errorf("not enough args, placeholder count is greater than %d", len(args))
+			return false
+		}
+		var literal string
+		if literal, err = formatSQLLiteral(args[argIndex]); err != nil {
+			return false
+		}
+		builder.WriteString(literal)
+		argIndex++
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	if argIndex != len(args) {
+		return "", fmt.Errorf("too many args, %d placeholders but %d args", argIndex, len(args))
+	}
+	return builder.String(), nil
+}
+
+// scanPlaceholders 按照 ? 占位符拆分 sql，字符串常量、`标识符`及注释中的 ? 不作为占位符，每拆分出一段回调一次 fn，
+// placeholder 为 true 表示该段是占位符，fn 返回 false 停止扫描
+func scanPlaceholders(sql string, fn func(seg string, placeholder bool) bool) {
+	scanSQLTokens(sql, func(sql string, i int) int {
+		if sql[i] == '?' {
+			return 1
+		}
+		return 0
+	}, fn)
+}
+
+// scanSQLTokens 扫描 sql，跳过字符串常量、`标识符`及注释，在其他位置使用 matchToken 识别 token，matchToken 返回 token 的长度，0 表示不是 token。
+// 每拆分出一段回调一次 fn，placeholder 为 true 表示该段是 token，fn 返回 false 停止扫描
+func scanSQLTokens(sql string, matchToken func(sql string, i int) int, fn func(seg string, placeholder bool) bool) {
+	start := 0
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i)
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "-- ")):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql) - 1
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql) - 1
+			}
+		default:
+			n := matchToken(sql, i)
+			if n == 0 {
+				continue
+			}
+			if !fn(sql[start:i], false) || !fn(sql[i:i+n], true) {
+				return
+			}
+			i += n - 1
+			start = i + 1
+		}
+	}
+	fn(sql[start:], false)
+}
+
+// skipQuoted 返回从 start 开始的引号内容的结束位置，支持 \ 转义以及两个连续引号的转义
+func skipQuoted(sql string, start int) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		c := sql[i]
+		if c == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if c == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql) - 1
+}
+
+// formatArg 把参数转换成驱动最终使用的值，比如调用 driver.Valuer、把各种整数转换成 int64，
+// Interpolate 与 sql 日志都使用它，保证二者输出的参数一致
+func formatArg(arg any) (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(arg)
+}
+
+// formatArgs 使用 formatArg 转换所有参数，无法转换的参数保持原值，用于输出日志
+func formatArgs(args []any) []any {
+	if len(args) == 0 {
+		return args
+	}
+	ret := make([]any, len(args))
+	for i, arg := range args {
+		value, err := formatArg(arg)
+		if err != nil {
+			ret[i] = arg
+			continue
+		}
+		ret[i] = value
+	}
+	return ret
+}
+
+func formatSQLLiteral(arg any) (string, error) {
+	value, err := formatArg(arg)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("invalid float value %v", v)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		if v.IsZero() {
+			return "'0000-00-00'", nil
+		}
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'", nil
+	case []byte:
+		if v == nil {
+			return "NULL", nil
+		}
+		return "X'" + utils.ToUpperHexString(v) + "'", nil
+	case string:
+		return quoteSQLString(v), nil
+	}
+	return "", fmt.Errorf("unsupported arg type %T", value)
+}
+
+// quoteSQLString 生成字符串常量，只转义单引号，含有 \ 或者控制字符时输出成十六进制，因此与 NO_BACKSLASH_ESCAPES 无关
+func quoteSQLString(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '\\' || c < 0x20 || c == 0x7f {
+			return "X'" + utils.ToUpperHexString([]byte(s)) + "'"
+		}
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
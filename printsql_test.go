@@ -0,0 +1,54 @@
+package daog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rolandhe/daog/ttypes"
+)
+
+func TestInterpolate(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sql, err := Interpolate("select * from t where a = ? and b = ? and c = ? and d = ? and e = '?' and f = ? and g = ? and h = ? and i = ?",
+		[]any{`it's "x"`, nil, []byte{0x01, 0xab}, at, 12, "a\\b", "a\nb", ttypes.NormalDatetime(at)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `select * from t where a = 'it''s "x"' and b = NULL and c = X'01AB' and d = '2024-01-02 03:04:05' and e = '?' and f = 12` +
+		` and g = X'615C62' and h = X'610A62' and i = '2024-01-02 03:04:05'`
+	if sql != expected {
+		t.Error(sql)
+	}
+
+	if _, err = Interpolate("select ? , ?", []any{1}); err == nil {
+		t.Error("missing arg should fail")
+	}
+	if _, err = Interpolate("select ?", []any{1, 2}); err == nil {
+		t.Error("extra arg should fail")
+	}
+}
+
+func TestBuildSelectIgnoresSharding(t *testing.T) {
+	meta := *filterSampleMeta
+	meta.ShardingFunc = func(tableName string, shardingKey any) string {
+		t.Error("sharding func should not be called")
+		return tableName
+	}
+	sql, _, err := BuildSelect(&meta, nil, nil, nil, NewView([]string{"id"}))
+	if err != nil || sql != "select id from filter_sample" {
+		t.Error(sql, err)
+	}
+	sql, _, err = BuildCount(&meta, nil)
+	if err != nil || sql != "select count(*) from filter_sample" {
+		t.Error(sql, err)
+	}
+}
+
+func TestFormatArgs(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	type custom struct{}
+	values := formatArgs([]any{int32(1), ttypes.NormalDatetime(at), custom{}})
+	if values[0] != int64(1) || values[1] != at || values[2] != (custom{}) {
+		t.Error(values)
+	}
+}
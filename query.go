@@ -5,16 +5,25 @@
 package daog
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 )
 
 var invalidBatchSizeError = errors.New("page size must be greater than 0")
 
+// ErrNotUnique QueryUnique 查询到多条数据时返回的错误
+var ErrNotUnique = errors.New("more than one row matched")
+
 // View 定义查询的视图
 type View struct {
 	viewColumns []string
 	include     bool
+	// Distinct 为 true 时生成 select distinct 语句
+	Distinct bool
+	// Hints 查询提示，可以为nil，参见 QueryHints
+	Hints *QueryHints
 }
 
 // NewView 创建view，指定的字段为视图包含的字段
@@ -33,6 +42,15 @@ func NewExcludeView(excludeColumns []string) *View {
 	}
 }
 
+// NewDistinctView 创建 select distinct 的视图，指定的字段为视图包含的字段
+func NewDistinctView(includeColumns []string) *View {
+	return &View{
+		viewColumns: includeColumns,
+		include:     true,
+		Distinct:    true,
+	}
+}
+
 // GetAll 查询表的所有数据
 // 可变参数 viewColumns：
 //
@@ -73,32 +91,15 @@ func GetById[T any](tc *TransContext, id int64, meta *TableMeta[T], viewColumns
 //
 //	compile生成的文件中会有表字段的常量，比如 GroupInfo.go 文件中的 GroupInfoFields.Id, 直接使用它，避免手动写字符串
 func GetByIdWithViewObj[T any](tc *TransContext, id int64, meta *TableMeta[T], view *View) (*T, error) {
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
-	}
-	m.Eq(fieldId, id)
+	m := NewMatcher().Eq(meta.idColumn(), id)
 	return QueryOneMatcherWithViewObj(tc, m, meta, view)
 }
 
 // GetByIdForUpdate  类似 GetById， 只是支持 for update
 // skipLocked, true 需要 SKIP LOCKED
 func GetByIdForUpdate[T any](tc *TransContext, id int64, meta *TableMeta[T], skipLocked bool, viewColumns ...string) (*T, error) {
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
-	}
-	m.Eq(fieldId, id)
-	list, err := QueryListMatcherWithViewColumnsForUpdate(tc, m, meta, viewColumns, skipLocked)
-	if err != nil {
-		return nil, err
-	}
-	if len(list) == 0 {
-		return nil, nil
-	}
-	return list[0], nil
+	m := NewMatcher().Eq(meta.idColumn(), id)
+	return QueryOneMatcherForUpdate(tc, m, meta, skipLocked, viewColumns...)
 }
 
 // GetByIds 根据主键数组返回多条数据
@@ -120,33 +121,82 @@ func GetByIds[T any](tc *TransContext, ids []int64, meta *TableMeta[T], viewColu
 // view：查询视图
 //
 //	compile生成的文件中会有表字段的常量，比如 GroupInfo.go 文件中的 GroupInfoFields.Id, 直接使用它，避免手动写字符串
+//
+// 主键个数超过 MaxInSize 时，会分批查询并合并结果
 func GetByIdsWithViewObj[T any](tc *TransContext, ids []int64, meta *TableMeta[T], view *View) ([]*T, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
+	var ret []*T
+	for _, chunk := range splitInChunks(ids, MaxInSize) {
+		m := NewMatcher()
+		m.In(meta.idColumn(), ConvertToAnySlice(chunk))
+		list, err := QueryPageListMatcherWithViewObj(tc, m, meta, view, nil)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, list...)
 	}
-	m.In(fieldId, ConvertToAnySlice(ids))
+	return ret, nil
+}
 
-	return QueryPageListMatcherWithViewObj(tc, m, meta, view, nil)
+// GetByIdsInOrder 与 GetByIds 相同，但返回的数据按照 ids 中主键的顺序排列，不存在的主键被忽略，重复的主键只返回一次
+// 如果 viewColumns 中没有主键字段，会自动加入主键字段
+func GetByIdsInOrder[T any](tc *TransContext, ids []int64, meta *TableMeta[T], viewColumns ...string) ([]*T, error) {
+	idColumn := meta.idColumn()
+	if len(viewColumns) > 0 {
+		hasId := false
+		for _, c := range viewColumns {
+			if c == idColumn {
+				hasId = true
+				break
+			}
+		}
+		if !hasId {
+			viewColumns = append(viewColumns[:len(viewColumns):len(viewColumns)], idColumn)
+		}
+	}
+	list, err := GetByIds(tc, ids, meta, viewColumns...)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+	insMap := make(map[int64]*T, len(list))
+	for _, ins := range list {
+		id, err := idOfIns(meta, ins)
+		if err != nil {
+			return nil, err
+		}
+		insMap[id] = ins
+	}
+	ret := make([]*T, 0, len(list))
+	for _, id := range ids {
+		if ins, ok := insMap[id]; ok {
+			ret = append(ret, ins)
+			delete(insMap, id)
+		}
+	}
+	return ret, nil
 }
 
 // GetByIdsForUpdate  类似 GetByIds， 只是支持 for update
 // skipLocked, true 需要 SKIP LOCKED
+//
+// 主键个数超过 MaxInSize 时，会分批查询并合并结果
 func GetByIdsForUpdate[T any](tc *TransContext, ids []int64, meta *TableMeta[T], skipLocked bool, viewColumns ...string) ([]*T, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
+	var ret []*T
+	for _, chunk := range splitInChunks(ids, MaxInSize) {
+		m := NewMatcher()
+		m.In(meta.idColumn(), ConvertToAnySlice(chunk))
+		list, err := QueryListMatcherWithViewColumnsForUpdate(tc, m, meta, viewColumns, skipLocked)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, list...)
 	}
-	m.In(fieldId, ConvertToAnySlice(ids))
-	return QueryListMatcherWithViewColumnsForUpdate(tc, m, meta, viewColumns, skipLocked)
+	return ret, nil
 }
 
 // QueryListMatcher 根据查询条件 Matcher 返回多条数据， 通过与 Matcher 有关的相关函数来构建查询条件
@@ -199,6 +249,8 @@ func QueryListMatcherWithViewColumnsForUpdate[T any](tc *TransContext, m Matcher
 //	每个条件可以指定排序表字段名及是否是升序要求
 //
 // pager 参数，可以为nil，如果为nil，不分页
+//
+// m 为 nil 时同样会输出 order by 及 limit，早期版本会忽略它们而返回全表数据
 func QueryPageListMatcher[T any](tc *TransContext, m Matcher, meta *TableMeta[T], pager *Pager, orders ...*Order) ([]*T, error) {
 	return QueryPageListMatcherWithViewColumns(tc, m, meta, nil, pager, orders...)
 }
@@ -242,9 +294,11 @@ func QueryPageListMatcherWithViewObj[T any](tc *TransContext, m Matcher, meta *T
 	if err != nil {
 		return nil, err
 	}
-	return queryRawSQLCore(tc, func() (*T, []any) {
-		return buildInsInfoOfRow(meta, view)
-	}, sql, args...)
+	return queryWithCache(tc, meta, sql, args, func() ([]*T, error) {
+		return queryRawSQLCore(tc, func() (*T, []any) {
+			return buildInsInfoOfRow(meta, view)
+		}, sql, args...)
+	})
 }
 
 // QueryPageListMatcherWithViewColumnsForUpdate 与 QueryPageListMatcherWithViewColumns 类似， 只是支持 for update
@@ -254,6 +308,13 @@ func QueryPageListMatcherWithViewColumnsForUpdate[T any](tc *TransContext, m Mat
 		viewColumns: viewColumns,
 		include:     true,
 	}
+	return QueryPageListMatcherWithViewObjForUpdate(tc, m, meta, view, pager, skipLocked, orders...)
+}
+
+// QueryPageListMatcherWithViewObjForUpdate 与 QueryPageListMatcherWithViewObj 类似， 只是支持 for update，view 中的 Hints 同样生效，
+// 比如通过 force index 保证加锁时使用指定的索引，pager 可以为nil
+// skipLocked, true 需要 SKIP LOCKED
+func QueryPageListMatcherWithViewObjForUpdate[T any](tc *TransContext, m Matcher, meta *TableMeta[T], view *View, pager *Pager, skipLocked bool, orders ...*Order) ([]*T, error) {
 	sql, params, err := selectQuery(meta, tc.ctx, m, pager, orders, view)
 	if err != nil {
 		return nil, err
@@ -293,7 +354,7 @@ func QueryListMatcherWithViewColumnsByBatchHandle[T any](tc *TransContext, m Mat
 	}
 	var pager *Pager
 	if totalLimit > 0 {
-		pager = &Pager{0, totalLimit}
+		pager = &Pager{totalLimit, 1}
 	}
 	sql, args, err := selectQuery(meta, tc.ctx, m, pager, orders, view)
 	if err != nil {
@@ -314,18 +375,34 @@ func QueryOneMatcher[T any](tc *TransContext, m Matcher, meta *TableMeta[T], vie
 	})
 }
 
-// QueryOneMatcherWithViewObj 通过 Matcher 条件来查询，但只返回单条数据
+// QueryOneMatcherWithViewObj 通过 Matcher 条件来查询，但只返回单条数据，生成的sql带有 limit 1
 // view 视图
 func QueryOneMatcherWithViewObj[T any](tc *TransContext, m Matcher, meta *TableMeta[T], view *View) (*T, error) {
-	var err error
-	err = tc.check()
+	sql, args, err := selectQuery(meta, tc.ctx, m, NewPager(1, 1), nil, view)
 	if err != nil {
 		return nil, err
 	}
-	sql, args, err := selectQuery(meta, tc.ctx, m, nil, nil, view)
+	ret, err := queryWithCache(tc, meta, sql, args, func() ([]*T, error) {
+		ins, err := queryOneRawSQLCore(tc, meta, view, sql, args)
+		if err != nil || ins == nil {
+			return nil, err
+		}
+		return []*T{ins}, nil
+	})
+	if err != nil || len(ret) == 0 {
+		return nil, err
+	}
+	return ret[0], nil
+}
+
+func queryOneRawSQLCore[T any](tc *TransContext, meta *TableMeta[T], view *View, sql string, args []any) (*T, error) {
+	err := tc.check()
 	if err != nil {
 		return nil, err
 	}
+	if err = guardQuery(tc, sql, args); err != nil {
+		return nil, err
+	}
 	if tc.LogSQL {
 		sqlMd5 := traceLogSQLBefore(tc.ctx, sql, args)
 		defer traceLogSQLAfter(tc.ctx, sqlMd5, time.Now().UnixMilli())
@@ -345,16 +422,46 @@ func QueryOneMatcherWithViewObj[T any](tc *TransContext, m Matcher, meta *TableM
 	return ins, nil
 }
 
-// QueryOneMatcherForUpdate 与 QueryOneMatcher， 只是支持 for update
+// QueryOneMatcherForUpdate 与 QueryOneMatcher， 只是支持 for update，生成的sql带有 limit 1，只锁定读取的一条数据
 // skipLocked, true 需要 SKIP LOCKED
 func QueryOneMatcherForUpdate[T any](tc *TransContext, m Matcher, meta *TableMeta[T], skipLocked bool, viewColumns ...string) (*T, error) {
-	rows, err := QueryListMatcherWithViewColumnsForUpdate(tc, m, meta, viewColumns, skipLocked)
+	rows, err := QueryPageListMatcherWithViewColumnsForUpdate(tc, m, meta, viewColumns, NewPager(1, 1), skipLocked)
 	if err != nil {
 		return nil, err
 	}
 	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// QueryUnique 通过 Matcher 条件查询唯一的一条数据，生成的sql带有 limit 2，没有数据时返回 nil，有多条数据满足条件时返回 ErrNotUnique
+// 可变参数 viewColumns 指定需要查询的表字段，不指定表示要查询所有的表字段
+func QueryUnique[T any](tc *TransContext, m Matcher, meta *TableMeta[T], viewColumns ...string) (*T, error) {
+	rows, err := QueryPageListMatcherWithViewColumns(tc, m, meta, viewColumns, NewPager(2, 1))
+	if err != nil {
+		return nil, err
+	}
+	return uniqueRow(rows)
+}
+
+// QueryUniqueForUpdate 与 QueryUnique 类似， 只是支持 for update
+// skipLocked, true 需要 SKIP LOCKED
+func QueryUniqueForUpdate[T any](tc *TransContext, m Matcher, meta *TableMeta[T], skipLocked bool, viewColumns ...string) (*T, error) {
+	rows, err := QueryPageListMatcherWithViewColumnsForUpdate(tc, m, meta, viewColumns, NewPager(2, 1), skipLocked)
+	if err != nil {
 		return nil, err
 	}
+	return uniqueRow(rows)
+}
+
+func uniqueRow[T any](rows []*T) (*T, error) {
+	if len(rows) > 1 {
+		return nil, ErrNotUnique
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
 	return rows[0], nil
 }
 
@@ -371,6 +478,52 @@ func QueryRawSQL[T any](tc *TransContext, extract ExtractScanFieldPoints[T], sql
 	}, sql, args...)
 }
 
+// QueryRawSQLWithMeta 执行原生select sql语句，与 QueryRawSQL 不同的是不需要 ExtractScanFieldPoints，
+// 它读取结果集的字段名并通过 meta.LookupFieldFunc 找到 T 中对应的 field，select 字段的顺序可以任意调整，
+// 结果集中 T 不包含的字段会被丢弃，如果需要报错请使用 QueryRawSQLWithMetaStrict
+func QueryRawSQLWithMeta[T any](tc *TransContext, meta *TableMeta[T], sql string, args ...any) ([]*T, error) {
+	return queryRawSQLWithMetaCore(tc, meta, false, sql, args)
+}
+
+// QueryRawSQLWithMetaStrict 与 QueryRawSQLWithMeta 类似，但结果集中存在 T 不包含的字段时返回 ErrUnknownColumn
+func QueryRawSQLWithMetaStrict[T any](tc *TransContext, meta *TableMeta[T], sql string, args ...any) ([]*T, error) {
+	return queryRawSQLWithMetaCore(tc, meta, true, sql, args)
+}
+
+func queryRawSQLWithMetaCore[T any](tc *TransContext, meta *TableMeta[T], strict bool, sqlText string, args []any) ([]*T, error) {
+	var inses []*T
+	err := queryRowsCore(tc, sqlText, args, func(rows *sql.Rows) error {
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		probe := new(T)
+		for _, column := range columns {
+			if strict && meta.LookupFieldFunc(column, probe, true) == nil {
+				return fmt.Errorf("%w: result column %q is not a column of table %s", ErrUnknownColumn, column, meta.Table)
+			}
+		}
+		for rows.Next() {
+			ins := new(T)
+			scanFields := make([]any, len(columns))
+			for i, column := range columns {
+				if scanFields[i] = meta.LookupFieldFunc(column, ins, true); scanFields[i] == nil {
+					scanFields[i] = new(any)
+				}
+			}
+			if err = rows.Scan(scanFields...); err != nil {
+				return err
+			}
+			inses = append(inses, ins)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inses, nil
+}
+
 // QueryRawSQLByBatchHandle 与 QueryRawSQL 和 QueryListMatcherByBatchHandle 结合体，执行原生的sql语句，但通过回调 BatchHandler 进行分批业务处理
 // batchSize 每批处理数据的最大容量，必须大于0，但不要设置太大，当设置为1时，退化成每条处理
 // handler 用于处理每批数据的函数
@@ -381,18 +534,115 @@ func QueryRawSQLByBatchHandle[T any](tc *TransContext, batchSize int, handler Ba
 	}, sql, args...)
 }
 
+// Pluck 只查询满足条件的数据中 column 一个字段的值，值直接读取到 V 类型的 slice 中，不会创建表实体对象，比如只需要查询 id 的场景:
+//
+//	ids, err := daog.Pluck[int64](tc, dal.GroupInfoMeta, dal.GroupInfoFields.Id, m)
+//
+// V 必须是能够接收该字段值的类型，与 T 中对应 field 的类型相同即可
+func Pluck[V any, T any](tc *TransContext, meta *TableMeta[T], column string, m Matcher, orders ...*Order) ([]V, error) {
+	return pluckCore[V](tc, meta, &View{viewColumns: []string{column}, include: true}, m, orders)
+}
+
+// PluckDistinct 与 Pluck 相同，但生成 select distinct，返回的值不重复。
+// mysql 不允许 distinct 查询按照未被查询的字段排序，所以 orders 只能使用 column 本身，否则返回错误
+func PluckDistinct[V any, T any](tc *TransContext, meta *TableMeta[T], column string, m Matcher, orders ...*Order) ([]V, error) {
+	for _, order := range orders {
+		if order.ColumnName != column {
+			return nil, fmt.Errorf("pluck distinct %s can't order by other column %s", column, order.ColumnName)
+		}
+	}
+	return pluckCore[V](tc, meta, NewDistinctView([]string{column}), m, orders)
+}
+
+func pluckCore[V any, T any](tc *TransContext, meta *TableMeta[T], view *View, m Matcher, orders []*Order) ([]V, error) {
+	stmt, args, err := selectQuery(meta, tc.ctx, m, nil, orders, view)
+	if err != nil {
+		return nil, err
+	}
+	var ret []V
+	err = queryRowsCore(tc, stmt, args, func(rows *sql.Rows) error {
+		for rows.Next() {
+			var v V
+			if err := rows.Scan(&v); err != nil {
+				return err
+			}
+			ret = append(ret, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Exists 判断是否存在满足 Matcher 条件的数据，执行 select 1 from tab where ... limit 1，找到第一条即返回，比 Count 更高效
+func Exists[T any](tc *TransContext, m Matcher, meta *TableMeta[T]) (bool, error) {
+	return ExistsWithHints(tc, m, meta, nil)
+}
+
+// ExistsWithHints 与 Exists 类似，hints 指定查询提示，可以为nil，参见 QueryHints
+func ExistsWithHints[T any](tc *TransContext, m Matcher, meta *TableMeta[T], hints *QueryHints) (bool, error) {
+	sql, args, err := existsQuery(meta, tc.ctx, m, hints)
+	if err != nil {
+		return false, err
+	}
+	return existsCore(tc, sql, args)
+}
+
+// ExistsForUpdate 与 Exists 类似， 只是支持 for update，可以在事务内锁定满足条件的记录或者间隙，用于先检查后插入的场景
+// skipLocked, true 需要 SKIP LOCKED
+func ExistsForUpdate[T any](tc *TransContext, m Matcher, meta *TableMeta[T], skipLocked bool) (bool, error) {
+	return ExistsForUpdateWithHints(tc, m, meta, nil, skipLocked)
+}
+
+// ExistsForUpdateWithHints 与 ExistsForUpdate 类似，hints 指定查询提示，可以为nil，
+// 比如通过 force index 保证加锁时使用指定的索引，避免锁住过多的记录
+func ExistsForUpdateWithHints[T any](tc *TransContext, m Matcher, meta *TableMeta[T], hints *QueryHints, skipLocked bool) (bool, error) {
+	sql, args, err := existsQuery(meta, tc.ctx, m, hints)
+	if err != nil {
+		return false, err
+	}
+	if !skipLocked {
+		sql = sql + " for update"
+	} else {
+		sql = sql + " for update skip locked"
+	}
+	return existsCore(tc, sql, args)
+}
+
+func existsCore(tc *TransContext, sqlText string, args []any) (bool, error) {
+	exists := false
+	err := queryRowsCore(tc, sqlText, args, func(rows *sql.Rows) error {
+		exists = rows.Next()
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
 // Count 表达 select count(*)  语义，其条件通过 Matcher 确定
 func Count[T any](tc *TransContext, m Matcher, meta *TableMeta[T]) (int64, error) {
+	return CountWithHints(tc, m, meta, nil)
+}
+
+// CountWithHints 与 Count 类似，hints 指定查询提示，可以为nil，参见 QueryHints
+func CountWithHints[T any](tc *TransContext, m Matcher, meta *TableMeta[T], hints *QueryHints) (int64, error) {
 	var err error
 	err = tc.check()
 	if err != nil {
 		return 0, err
 	}
-	sql, args, err := countQuery(meta, tc.ctx, m)
+	sql, args, err := countQuery(meta, tc.ctx, m, hints)
 	if err != nil {
 		return 0, err
 	}
 
+	if err = guardQuery(tc, sql, args); err != nil {
+		return 0, err
+	}
 	if tc.LogSQL {
 		sqlMd5 := traceLogSQLBefore(tc.ctx, sql, args)
 		defer traceLogSQLAfter(tc.ctx, sqlMd5, time.Now().UnixMilli())
@@ -414,6 +664,30 @@ func Count[T any](tc *TransContext, m Matcher, meta *TableMeta[T]) (int64, error
 
 type rowInsCreate[T any] func() (*T, []any)
 
+// queryRowsCore 执行查询并把 sql.Rows 交给 handle 处理，负责检查事务状态、输出sql日志以及关闭 sql.Rows
+func queryRowsCore(tc *TransContext, sql string, args []any, handle func(rows *sql.Rows) error) error {
+	err := tc.check()
+	if err != nil {
+		return err
+	}
+	if err = guardQuery(tc, sql, args); err != nil {
+		return err
+	}
+	if tc.LogSQL {
+		sqlMd5 := traceLogSQLBefore(tc.ctx, sql, args)
+		defer traceLogSQLAfter(tc.ctx, sqlMd5, time.Now().UnixMilli())
+	}
+	rows, err := tc.conn.QueryContext(tc.ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if err = handle(rows); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
 func queryRawSQLByBatchHandleCore[T any](tc *TransContext, batchSize int, handler BatchHandler[T], creatorFunc rowInsCreate[T], sql string, args ...any) error {
 	var err error
 	err = tc.check()
@@ -421,6 +695,9 @@ func queryRawSQLByBatchHandleCore[T any](tc *TransContext, batchSize int, handle
 		return err
 	}
 
+	if err = guardQuery(tc, sql, args); err != nil {
+		return err
+	}
 	if tc.LogSQL {
 		sqlMd5 := traceLogSQLBefore(tc.ctx, sql, args)
 		defer traceLogSQLAfter(tc.ctx, sqlMd5, time.Now().UnixMilli())
@@ -467,6 +744,9 @@ func queryRawSQLCore[T any](tc *TransContext, creatorFunc rowInsCreate[T], sql s
 		return nil, err
 	}
 
+	if err = guardQuery(tc, sql, args); err != nil {
+		return nil, err
+	}
 	if tc.LogSQL {
 		sqlMd5 := traceLogSQLBefore(tc.ctx, sql, args)
 		defer traceLogSQLAfter(tc.ctx, sqlMd5, time.Now().UnixMilli())
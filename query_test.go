@@ -0,0 +1,86 @@
+package daog
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestQueryRawSQLWithMetaStrict(t *testing.T) {
+	db := &fakeDB{columns: []string{"id", "name", "total"}, rows: [][]driver.Value{{int64(1), "a", int64(3)}}}
+	tc := newFakeTc(t, db)
+	stmt := "select id,name,count(*) as total from filter_sample group by id"
+
+	list, err := QueryRawSQLWithMeta(tc, filterSampleMeta, stmt)
+	if err != nil || len(list) != 1 || list[0].Id != 1 || list[0].Name != "a" {
+		t.Error(list, err)
+	}
+
+	list, err = QueryRawSQLWithMetaStrict(tc, filterSampleMeta, stmt)
+	if !errors.Is(err, ErrUnknownColumn) || list != nil {
+		t.Error(list, err)
+	}
+
+	db.columns = []string{"id", "name"}
+	db.rows = [][]driver.Value{{int64(2), "b"}}
+	list, err = QueryRawSQLWithMetaStrict(tc, filterSampleMeta, "select id,name from filter_sample where id = ?", 2)
+	if err != nil || len(list) != 1 || list[0].Id != 2 || list[0].Name != "b" {
+		t.Error(list, err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	db := &fakeDB{columns: []string{"1"}, rows: [][]driver.Value{{int64(1)}}}
+	tc := newFakeTc(t, db)
+	exists, err := Exists(tc, NewMatcher().Eq("status", 1), filterSampleMeta)
+	if err != nil || !exists || db.lastQuery() != "select 1 from filter_sample where status = ? limit 1" {
+		t.Error(exists, err, db.lastQuery())
+	}
+
+	db.rows = nil
+	exists, err = Exists(tc, nil, filterSampleMeta)
+	if err != nil || exists || db.lastQuery() != "select 1 from filter_sample limit 1" {
+		t.Error(exists, err, db.lastQuery())
+	}
+
+	exists, err = ExistsForUpdate(tc, NewMatcher().Eq("id", 1), filterSampleMeta, true)
+	if err != nil || exists || db.lastQuery() != "select 1 from filter_sample where id = ? limit 1 for update skip locked" {
+		t.Error(exists, err, db.lastQuery())
+	}
+}
+
+func TestQueryOneAndUnique(t *testing.T) {
+	db := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}}
+	tc := newFakeTc(t, db)
+	m := NewMatcher().Eq("status", 1)
+
+	one, err := QueryOneMatcher(tc, m, filterSampleMeta, "id")
+	if err != nil || one == nil || one.Id != 1 || db.lastQuery() != "select id from filter_sample where status = ? limit 1" {
+		t.Error(one, err, db.lastQuery())
+	}
+	one, err = QueryOneMatcherForUpdate(tc, m, filterSampleMeta, false, "id")
+	if err != nil || one == nil || one.Id != 1 || db.lastQuery() != "select id from filter_sample where status = ? limit 1 for update" {
+		t.Error(one, err, db.lastQuery())
+	}
+
+	one, err = QueryUnique(tc, m, filterSampleMeta, "id")
+	if !errors.Is(err, ErrNotUnique) || one != nil || db.lastQuery() != "select id from filter_sample where status = ? limit 2" {
+		t.Error(one, err, db.lastQuery())
+	}
+	one, err = QueryUniqueForUpdate(tc, m, filterSampleMeta, true, "id")
+	if !errors.Is(err, ErrNotUnique) || one != nil || db.lastQuery() != "select id from filter_sample where status = ? limit 2 for update skip locked" {
+		t.Error(one, err, db.lastQuery())
+	}
+
+	db.rows = db.rows[:1]
+	if one, err = QueryUnique(tc, m, filterSampleMeta, "id"); err != nil || one == nil || one.Id != 1 {
+		t.Error(one, err)
+	}
+	db.rows = nil
+	if one, err = QueryUnique(tc, m, filterSampleMeta, "id"); err != nil || one != nil {
+		t.Error(one, err)
+	}
+	if one, err = QueryOneMatcher(tc, m, filterSampleMeta, "id"); err != nil || one != nil {
+		t.Error(one, err)
+	}
+}
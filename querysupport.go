@@ -4,7 +4,10 @@
 
 package daog
 
+import "errors"
 
+// ErrInvalidPager Pager 的 PageSize 或者 PageNumber 小于1时返回的错误
+var ErrInvalidPager = errors.New("invalid pager: page size and page number must be greater than 0")
 
 // Pager 分页参数结构，PageSize 每一页的大小，PageNumber 页码，从1算起
 type Pager struct {
@@ -16,6 +19,18 @@ func NewPager(pageSize int, pageNumber int) *Pager {
 	return &Pager{pageSize, pageNumber}
 }
 
+// Validate 校验分页参数，PageSize 和 PageNumber 都必须大于0，否则会生成负数的 offset
+func (pager *Pager) Validate() error {
+	if pager.PageSize <= 0 || pager.PageNumber <= 0 {
+		return ErrInvalidPager
+	}
+	return nil
+}
+
+func (pager *Pager) offset() int64 {
+	return int64(pager.PageNumber-1) * int64(pager.PageSize)
+}
+
 
 // Order 描述sql中的单个 order 条件
 type Order struct {
@@ -25,6 +25,9 @@ type QuickDao[T any] interface {
 	// GetByIds 封装 GetByIds 函数
 	GetByIds(tc *TransContext, ids []int64, viewColumns ...string) ([]*T, error)
 
+	// GetByIdsInOrder 封装 GetByIdsInOrder 函数
+	GetByIdsInOrder(tc *TransContext, ids []int64, viewColumns ...string) ([]*T, error)
+
 	// GetByIdsWithViewObj 封装 GetByIdsWithViewObj 函数
 	GetByIdsWithViewObj(tc *TransContext, ids []int64, view *View) ([]*T, error)
 
@@ -53,6 +56,46 @@ type QuickDao[T any] interface {
 
 	// QueryPageListMatcherWithViewColumnsForUpdate 封装 QueryPageListMatcherWithViewColumnsForUpdate 函数
 	QueryPageListMatcherWithViewColumnsForUpdate(tc *TransContext, m Matcher, viewColumns []string, pager *Pager, skipLocked bool, orders ...*Order) ([]*T, error)
+	// QueryPageListMatcherWithViewObjForUpdate 封装 QueryPageListMatcherWithViewObjForUpdate 函数
+	QueryPageListMatcherWithViewObjForUpdate(tc *TransContext, m Matcher, view *View, pager *Pager, skipLocked bool, orders ...*Order) ([]*T, error)
+	// GetByKey 封装 GetByKey 函数
+	GetByKey(tc *TransContext, keyValues ...any) (*T, error)
+	// GetByKeys 封装 GetByKeys 函数
+	GetByKeys(tc *TransContext, keys [][]any, viewColumns ...string) ([]*T, error)
+	// UpdateByKey 封装 UpdateByKey 函数
+	UpdateByKey(tc *TransContext, modifier Modifier, keyValues ...any) (int64, error)
+	// DeleteByKey 封装 DeleteByKey 函数
+	DeleteByKey(tc *TransContext, keyValues ...any) (int64, error)
+	// DeleteByKeys 封装 DeleteByKeys 函数
+	DeleteByKeys(tc *TransContext, keys [][]any) (int64, error)
+	// QueryUnique 封装 QueryUnique 函数
+	QueryUnique(tc *TransContext, m Matcher, viewColumns ...string) (*T, error)
+	// QueryUniqueForUpdate 封装 QueryUniqueForUpdate 函数
+	QueryUniqueForUpdate(tc *TransContext, m Matcher, skipLocked bool, viewColumns ...string) (*T, error)
+	// Exists 封装 Exists 函数
+	Exists(tc *TransContext, m Matcher) (bool, error)
+	// ExistsForUpdate 封装 ExistsForUpdate 函数
+	ExistsForUpdate(tc *TransContext, m Matcher, skipLocked bool) (bool, error)
+	// ExistsWithHints 封装 ExistsWithHints 函数
+	ExistsWithHints(tc *TransContext, m Matcher, hints *QueryHints) (bool, error)
+	// ExistsForUpdateWithHints 封装 ExistsForUpdateWithHints 函数
+	ExistsForUpdateWithHints(tc *TransContext, m Matcher, hints *QueryHints, skipLocked bool) (bool, error)
+	// QueryRowsMatcher 封装 QueryRowsMatcher 函数
+	QueryRowsMatcher(tc *TransContext, m Matcher, orders ...*Order) (*Rows[T], error)
+	// QueryRowsMatcherWithViewObj 封装 QueryRowsMatcherWithViewObj 函数
+	QueryRowsMatcherWithViewObj(tc *TransContext, m Matcher, view *View, orders ...*Order) (*Rows[T], error)
+	// QueryPage 封装 QueryPage 函数
+	QueryPage(tc *TransContext, m Matcher, pager *Pager, orders ...*Order) (*PageResult[T], error)
+	// QueryPageWithOptions 封装 QueryPageWithOptions 函数
+	QueryPageWithOptions(tc *TransContext, m Matcher, view *View, pager *Pager, options *PageQueryOptions, orders ...*Order) (*PageResult[T], error)
+	// QueryCursorPageListMatcher 封装 QueryCursorPageListMatcher 函数
+	QueryCursorPageListMatcher(tc *TransContext, m Matcher, pager *CursorPager, orders ...*Order) ([]*T, string, error)
+	// QueryCursorPageListMatcherWithViewColumns 封装 QueryCursorPageListMatcherWithViewColumns 函数
+	QueryCursorPageListMatcherWithViewColumns(tc *TransContext, m Matcher, viewColumns []string, pager *CursorPager, orders ...*Order) ([]*T, string, error)
+	// QueryCursorPageListMatcherWithViewObj 封装 QueryCursorPageListMatcherWithViewObj 函数
+	QueryCursorPageListMatcherWithViewObj(tc *TransContext, m Matcher, view *View, pager *CursorPager, orders ...*Order) ([]*T, string, error)
+	// QueryCursorPageListMatcherForUpdate 封装 QueryCursorPageListMatcherForUpdate 函数
+	QueryCursorPageListMatcherForUpdate(tc *TransContext, m Matcher, viewColumns []string, pager *CursorPager, skipLocked bool, orders ...*Order) ([]*T, string, error)
 	// QueryListMatcherByBatchHandle 封装 QueryListMatcherByBatchHandle 函数
 	QueryListMatcherByBatchHandle(tc *TransContext, m Matcher, totalLimit int, batchSize int, handler BatchHandler[T], orders ...*Order) error
 	// QueryListMatcherWithViewColumnsByBatchHandle 封装 QueryListMatcherWithViewColumnsByBatchHandle 函数
@@ -72,6 +115,8 @@ type QuickDao[T any] interface {
 
 	// Count 封装 Count 函数
 	Count(tc *TransContext, m Matcher) (int64, error)
+	// CountWithHints 封装 CountWithHints 函数
+	CountWithHints(tc *TransContext, m Matcher, hints *QueryHints) (int64, error)
 
 	// Insert 封装 Insert 函数
 	Insert(tc *TransContext, ins *T) (int64, error)
@@ -129,6 +174,10 @@ func (dao *baseQuickDao[T]) GetByIds(tc *TransContext, ids []int64, viewColumns
 	return GetByIds(tc, ids, dao.meta, viewColumns...)
 }
 
+func (dao *baseQuickDao[T]) GetByIdsInOrder(tc *TransContext, ids []int64, viewColumns ...string) ([]*T, error) {
+	return GetByIdsInOrder(tc, ids, dao.meta, viewColumns...)
+}
+
 func (dao *baseQuickDao[T]) GetByIdsWithViewObj(tc *TransContext, ids []int64, view *View) ([]*T, error) {
 	return GetByIdsWithViewObj(tc, ids, dao.meta, view)
 }
@@ -177,6 +226,86 @@ func (dao *baseQuickDao[T]) QueryPageListMatcherWithViewColumnsForUpdate(tc *Tra
 	return QueryPageListMatcherWithViewColumnsForUpdate(tc, m, dao.meta, viewColumns, pager, skipLocked, orders...)
 }
 
+func (dao *baseQuickDao[T]) QueryPageListMatcherWithViewObjForUpdate(tc *TransContext, m Matcher, view *View, pager *Pager, skipLocked bool, orders ...*Order) ([]*T, error) {
+	return QueryPageListMatcherWithViewObjForUpdate(tc, m, dao.meta, view, pager, skipLocked, orders...)
+}
+
+func (dao *baseQuickDao[T]) GetByKey(tc *TransContext, keyValues ...any) (*T, error) {
+	return GetByKey(tc, dao.meta, keyValues...)
+}
+
+func (dao *baseQuickDao[T]) GetByKeys(tc *TransContext, keys [][]any, viewColumns ...string) ([]*T, error) {
+	return GetByKeys(tc, dao.meta, keys, viewColumns...)
+}
+
+func (dao *baseQuickDao[T]) UpdateByKey(tc *TransContext, modifier Modifier, keyValues ...any) (int64, error) {
+	return UpdateByKey(tc, dao.meta, modifier, keyValues...)
+}
+
+func (dao *baseQuickDao[T]) DeleteByKey(tc *TransContext, keyValues ...any) (int64, error) {
+	return DeleteByKey(tc, dao.meta, keyValues...)
+}
+
+func (dao *baseQuickDao[T]) DeleteByKeys(tc *TransContext, keys [][]any) (int64, error) {
+	return DeleteByKeys(tc, dao.meta, keys)
+}
+
+func (dao *baseQuickDao[T]) QueryUnique(tc *TransContext, m Matcher, viewColumns ...string) (*T, error) {
+	return QueryUnique(tc, m, dao.meta, viewColumns...)
+}
+
+func (dao *baseQuickDao[T]) QueryUniqueForUpdate(tc *TransContext, m Matcher, skipLocked bool, viewColumns ...string) (*T, error) {
+	return QueryUniqueForUpdate(tc, m, dao.meta, skipLocked, viewColumns...)
+}
+
+func (dao *baseQuickDao[T]) Exists(tc *TransContext, m Matcher) (bool, error) {
+	return Exists(tc, m, dao.meta)
+}
+
+func (dao *baseQuickDao[T]) ExistsForUpdate(tc *TransContext, m Matcher, skipLocked bool) (bool, error) {
+	return ExistsForUpdate(tc, m, dao.meta, skipLocked)
+}
+
+func (dao *baseQuickDao[T]) ExistsWithHints(tc *TransContext, m Matcher, hints *QueryHints) (bool, error) {
+	return ExistsWithHints(tc, m, dao.meta, hints)
+}
+
+func (dao *baseQuickDao[T]) ExistsForUpdateWithHints(tc *TransContext, m Matcher, hints *QueryHints, skipLocked bool) (bool, error) {
+	return ExistsForUpdateWithHints(tc, m, dao.meta, hints, skipLocked)
+}
+
+func (dao *baseQuickDao[T]) QueryRowsMatcher(tc *TransContext, m Matcher, orders ...*Order) (*Rows[T], error) {
+	return QueryRowsMatcher(tc, m, dao.meta, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryRowsMatcherWithViewObj(tc *TransContext, m Matcher, view *View, orders ...*Order) (*Rows[T], error) {
+	return QueryRowsMatcherWithViewObj(tc, m, dao.meta, view, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryPage(tc *TransContext, m Matcher, pager *Pager, orders ...*Order) (*PageResult[T], error) {
+	return QueryPage(tc, m, dao.meta, pager, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryPageWithOptions(tc *TransContext, m Matcher, view *View, pager *Pager, options *PageQueryOptions, orders ...*Order) (*PageResult[T], error) {
+	return QueryPageWithOptions(tc, m, dao.meta, view, pager, options, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryCursorPageListMatcher(tc *TransContext, m Matcher, pager *CursorPager, orders ...*Order) ([]*T, string, error) {
+	return QueryCursorPageListMatcher(tc, m, dao.meta, pager, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryCursorPageListMatcherWithViewColumns(tc *TransContext, m Matcher, viewColumns []string, pager *CursorPager, orders ...*Order) ([]*T, string, error) {
+	return QueryCursorPageListMatcherWithViewColumns(tc, m, dao.meta, viewColumns, pager, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryCursorPageListMatcherWithViewObj(tc *TransContext, m Matcher, view *View, pager *CursorPager, orders ...*Order) ([]*T, string, error) {
+	return QueryCursorPageListMatcherWithViewObj(tc, m, dao.meta, view, pager, orders...)
+}
+
+func (dao *baseQuickDao[T]) QueryCursorPageListMatcherForUpdate(tc *TransContext, m Matcher, viewColumns []string, pager *CursorPager, skipLocked bool, orders ...*Order) ([]*T, string, error) {
+	return QueryCursorPageListMatcherForUpdate(tc, m, dao.meta, viewColumns, pager, skipLocked, orders...)
+}
+
 func (dao *baseQuickDao[T]) QueryListMatcherByBatchHandle(tc *TransContext, m Matcher, totalLimit int, batchSize int, handler BatchHandler[T], orders ...*Order) error {
 	return QueryListMatcherByBatchHandle(tc, m, dao.meta, totalLimit, batchSize, handler, orders...)
 }
@@ -209,6 +338,10 @@ func (dao *baseQuickDao[T]) Count(tc *TransContext, m Matcher) (int64, error) {
 	return Count(tc, m, dao.meta)
 }
 
+func (dao *baseQuickDao[T]) CountWithHints(tc *TransContext, m Matcher, hints *QueryHints) (int64, error) {
+	return CountWithHints(tc, m, dao.meta, hints)
+}
+
 func (dao *baseQuickDao[T]) Insert(tc *TransContext, ins *T) (int64, error) {
 	return Insert(tc, ins, dao.meta)
 }
@@ -0,0 +1,138 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Rows 拉取式的查询结果迭代器，每次调用 Next 读取并映射一行数据，适合流式处理大量数据，与 BatchHandler 回调方式不同，
+// 每次 Value 返回的都是新创建的对象，可以放心持有。使用方式:
+//
+//	rows, err := daog.QueryRowsMatcher(tc, matcher, meta)
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//	for rows.Next() {
+//		ins := rows.Value()
+//		...
+//	}
+//	return rows.Err()
+//
+// Rows 在关闭之前会占用 TransContext 的数据库连接，在此期间不能使用同一个 TransContext 执行其他sql
+type Rows[T any] struct {
+	tc        *TransContext
+	rows      *sql.Rows
+	creator   rowInsCreate[T]
+	cur       *T
+	err       error
+	closed    bool
+	sqlMd5    string
+	startTime int64
+}
+
+// QueryRowsMatcher 根据查询条件 Matcher 返回 Rows 迭代器，orders 可变参数指定排序条件
+func QueryRowsMatcher[T any](tc *TransContext, m Matcher, meta *TableMeta[T], orders ...*Order) (*Rows[T], error) {
+	return QueryRowsMatcherWithViewObj(tc, m, meta, nil, orders...)
+}
+
+// QueryRowsMatcherWithViewColumns 与 QueryRowsMatcher 类似，viewColumns 指定需要查询的表字段名，可以传入 nil， 表示读取所有字段
+func QueryRowsMatcherWithViewColumns[T any](tc *TransContext, m Matcher, meta *TableMeta[T], viewColumns []string, orders ...*Order) (*Rows[T], error) {
+	view := &View{
+		viewColumns: viewColumns,
+		include:     true,
+	}
+	return QueryRowsMatcherWithViewObj(tc, m, meta, view, orders...)
+}
+
+// QueryRowsMatcherWithViewObj 与 QueryRowsMatcher 类似，view 指定需要查询的视图
+func QueryRowsMatcherWithViewObj[T any](tc *TransContext, m Matcher, meta *TableMeta[T], view *View, orders ...*Order) (*Rows[T], error) {
+	sql, args, err := selectQuery(meta, tc.ctx, m, nil, orders, view)
+	if err != nil {
+		return nil, err
+	}
+	return openRows(tc, func() (*T, []any) {
+		return buildInsInfoOfRow(meta, view)
+	}, sql, args...)
+}
+
+// QueryRawSQLRows 与 QueryRawSQL 类似，执行原生select sql语句，但返回 Rows 迭代器
+func QueryRawSQLRows[T any](tc *TransContext, extract ExtractScanFieldPoints[T], sql string, args ...any) (*Rows[T], error) {
+	return openRows(tc, func() (*T, []any) {
+		ins := new(T)
+		return ins, extract(ins)
+	}, sql, args...)
+}
+
+func openRows[T any](tc *TransContext, creatorFunc rowInsCreate[T], sql string, args ...any) (*Rows[T], error) {
+	err := tc.check()
+	if err != nil {
+		return nil, err
+	}
+	if err = guardQuery(tc, sql, args); err != nil {
+		return nil, err
+	}
+	r := &Rows[T]{tc: tc, creator: creatorFunc}
+	if tc.LogSQL {
+		r.sqlMd5 = traceLogSQLBefore(tc.ctx, sql, args)
+		r.startTime = time.Now().UnixMilli()
+	}
+	r.rows, err = tc.conn.QueryContext(tc.ctx, sql, args...)
+	if err != nil {
+		r.closed = true
+		r.logAfter()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Next 读取下一行数据，没有数据或者出错时返回 false 并自动关闭，出错信息通过 Err 获取
+func (r *Rows[T]) Next() bool {
+	if r.closed {
+		return false
+	}
+	if !r.rows.Next() {
+		r.err = r.rows.Err()
+		r.Close()
+		return false
+	}
+	ins, scanFields := r.creator()
+	if err := r.rows.Scan(scanFields...); err != nil {
+		r.err = err
+		r.Close()
+		return false
+	}
+	r.cur = ins
+	return true
+}
+
+// Value 返回 Next 读取的当前行数据
+func (r *Rows[T]) Value() *T {
+	return r.cur
+}
+
+// Err 返回迭代过程中发生的错误
+func (r *Rows[T]) Err() error {
+	return r.err
+}
+
+// Close 关闭迭代器并释放 sql.Rows，可以重复调用
+func (r *Rows[T]) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.cur = nil
+	err := r.rows.Close()
+	r.logAfter()
+	return err
+}
+
+func (r *Rows[T]) logAfter() {
+	if r.tc.LogSQL {
+		traceLogSQLAfter(r.tc.ctx, r.sqlMd5, r.startTime)
+	}
+}
@@ -0,0 +1,24 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+//go:build go1.23
+
+package daog
+
+import "iter"
+
+// All 把 Rows 适配成 iter.Seq2，可以使用 for ins, err := range rows.All() 遍历，迭代结束或者提前 break 时自动关闭 Rows,
+// 出错时最后一次迭代返回 nil 及错误
+func (r *Rows[T]) All() iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		defer r.Close()
+		for r.Next() {
+			if !yield(r.Value(), nil) {
+				return
+			}
+		}
+		if r.err != nil {
+			yield(nil, r.err)
+		}
+	}
+}
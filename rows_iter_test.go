@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package daog
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestRowsAll(t *testing.T) {
+	db := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}}
+	tc := newFakeTc(t, db)
+	rows, err := QueryRowsMatcherWithViewColumns(tc, nil, filterSampleMeta, []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for ins, err := range rows.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, ins.Id)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 || !rows.closed {
+		t.Error(ids, rows.closed)
+	}
+}
+
+func TestRowsAllError(t *testing.T) {
+	db := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {"abc"}}}
+	tc := newFakeTc(t, db)
+	rows, err := QueryRowsMatcherWithViewColumns(tc, nil, filterSampleMeta, []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*filterSample
+	var lastErr error
+	for ins, err := range rows.All() {
+		got = append(got, ins)
+		lastErr = err
+	}
+	if len(got) != 2 || got[0].Id != 1 || got[1] != nil || lastErr == nil || !rows.closed {
+		t.Error(got, lastErr)
+	}
+}
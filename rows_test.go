@@ -0,0 +1,50 @@
+package daog
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryRowsMatcher(t *testing.T) {
+	db := &fakeDB{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}}}
+	tc := newFakeTc(t, db)
+	rows, err := QueryRowsMatcherWithViewColumns(tc, NewMatcher().Eq("status", 1), filterSampleMeta, []string{"id", "name"}, NewOrder("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if db.lastQuery() != "select id,name from filter_sample where status = ? order by id" {
+		t.Error(db.lastQuery())
+	}
+	var list []*filterSample
+	for rows.Next() {
+		list = append(list, rows.Value())
+	}
+	if rows.Err() != nil || len(list) != 2 || list[0] == list[1] {
+		t.Fatal(list, rows.Err())
+	}
+	if list[0].Id != 1 || list[0].Name != "a" || list[1].Id != 2 || list[1].Name != "b" {
+		t.Error(list[0], list[1])
+	}
+	if !rows.closed || rows.Value() != nil || rows.Next() {
+		t.Error("rows should be closed after the last row")
+	}
+	if err = rows.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryRowsMatcherScanError(t *testing.T) {
+	db := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {"abc"}}}
+	tc := newFakeTc(t, db)
+	rows, err := QueryRowsMatcherWithViewColumns(tc, nil, filterSampleMeta, []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() || rows.Value().Id != 1 {
+		t.Fatal(rows.Err())
+	}
+	if rows.Next() || rows.Err() == nil || !rows.closed {
+		t.Error("scan error should stop and close rows", rows.Err())
+	}
+}
@@ -0,0 +1,222 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	txrequest "github.com/rolandhe/daog/tx"
+)
+
+// ChunkHandler 处理 ScanByPrimaryKeyChunks 读取的每块数据，tc 是本块数据所在的事务上下文，可以用它执行回写等操作，
+// 返回错误时扫描终止，该块不会触发 Checkpoint。只有 ScanOptions.TxRequest 为 txrequest.RequestWrite 时本块的写操作才会回滚，
+// 缺省的 txrequest.RequestNone 下每条sql单独提交，handler 出错之前已经执行的写操作不会回滚
+type ChunkHandler[T any] func(tc *TransContext, chunk []*T) error
+
+// ScanOptions ScanByPrimaryKeyChunksWithOptions 的选项
+type ScanOptions struct {
+	// StartId 断点续扫使用，只扫描主键大于 StartId 的数据，0 表示从头开始
+	StartId int64
+	// EndId 只扫描主键小于等于 EndId 的数据，只有 HasEndId 为 true 时生效
+	EndId int64
+	// HasEndId 为 true 时 EndId 才作为扫描的上限，否则没有上限
+	HasEndId bool
+	// TxRequest 每块数据所在事务的事务级别，缺省是 txrequest.RequestNone，handler 有写操作并且需要出错时回滚时应该设置为 txrequest.RequestWrite
+	TxRequest txrequest.RequestStyle
+	// TraceId 每块数据所在事务上下文的 trace id
+	TraceId string
+	// Throttle 每处理完一块数据后休眠的时间，用于降低对数据库的压力，0 表示不休眠
+	Throttle time.Duration
+	// Workers 并行扫描的协程数，大于1时先查询主键的最小值及最大值，然后把主键区间平均切分成互不相交的子区间，每个协程扫描一个子区间
+	Workers int
+	// OnSplit 并行扫描切分好区间、开始扫描之前回调，ranges 的下标就是协程序号，需要断点续扫时应该保存 ranges
+	OnSplit func(ranges []ScanRange)
+	// Ranges 并行扫描的断点续扫使用，每个元素对应一个协程，下标就是协程序号，设置后不再查询主键的最小值及最大值，
+	// StartId、EndId、HasEndId 及 Workers 被忽略。续扫时使用 OnSplit 保存的原始区间，并把每个区间的 From 替换成该协程 Checkpoint 记录的 lastId，
+	// From 大于等于 To 的区间已经扫描完成，会被跳过
+	Ranges []ScanRange
+	// Checkpoint 每块数据处理成功并提交后回调，worker 是协程序号(从0开始)，lastId 是该协程已经处理完成的最大主键，
+	// 并行扫描时每个协程的区间不同，需要按 worker 分别记录断点，续扫时通过 Ranges 传入，不能只用某一个 lastId 作为 StartId
+	Checkpoint func(worker int, lastId int64)
+}
+
+// ScanRange 并行扫描时一个协程负责的主键区间 (From, To]
+type ScanRange struct {
+	From int64
+	To   int64
+}
+
+// ScanByPrimaryKeyChunks 按照主键分块扫描整表，每块使用 id > lastId order by id limit chunkSize 查询，并在独立的短事务中回调 handler，
+// 与 QueryListMatcherByBatchHandle 相比不会长时间持有一个巨大的结果集，适合导出、回填等需要遍历大表的场景。
+// 表的主键必须是单一的整数字段，参见 TableMeta.PrimaryKeys，联合主键返回错误，m 指定额外的过滤条件，可以为 nil
+func ScanByPrimaryKeyChunks[T any](datasource Datasource, meta *TableMeta[T], m Matcher, chunkSize int, handler ChunkHandler[T]) error {
+	return ScanByPrimaryKeyChunksWithOptions(datasource, meta, m, chunkSize, nil, handler)
+}
+
+// ScanByPrimaryKeyChunksWithOptions 与 ScanByPrimaryKeyChunks 类似，options 支持断点续扫、限流以及并行扫描，可以为 nil
+func ScanByPrimaryKeyChunksWithOptions[T any](datasource Datasource, meta *TableMeta[T], m Matcher, chunkSize int, options *ScanOptions, handler ChunkHandler[T]) error {
+	if chunkSize <= 0 {
+		return invalidBatchSizeError
+	}
+	if keyColumns := meta.keyColumns(); len(keyColumns) != 1 || keyColumns[0] != meta.idColumn() {
+		return fmt.Errorf("%s: chunk scan needs a single integer primary key %s, but primary key is %v", meta.Table, meta.idColumn(), keyColumns)
+	}
+	if options == nil {
+		options = &ScanOptions{}
+	}
+	ranges := options.Ranges
+	if len(ranges) == 0 {
+		if options.Workers <= 1 {
+			return scanIdRange(datasource, meta, m, chunkSize, options, 0, options.StartId, options.EndId, options.HasEndId, nil, handler)
+		}
+		var err error
+		if ranges, err = splitIdRange(datasource, meta, m, options); err != nil || len(ranges) == 0 {
+			return err
+		}
+		if options.OnSplit != nil {
+			options.OnSplit(ranges)
+		}
+	}
+
+	var wg sync.WaitGroup
+	var stopped int32
+	var once sync.Once
+	var firstErr error
+	for i, r := range ranges {
+		if r.From >= r.To {
+			continue
+		}
+		wg.Add(1)
+		go func(worker int, from, to int64) {
+			defer wg.Done()
+			if err := scanIdRange(datasource, meta, m, chunkSize, options, worker, from, to, true, &stopped, handler); err != nil {
+				once.Do(func() {
+					firstErr = err
+				})
+				atomic.StoreInt32(&stopped, 1)
+			}
+		}(i, r.From, r.To)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// splitIdRange 查询主键的最小值及最大值，并把 (StartId, max] 切分成不超过 Workers 个区间，没有数据时返回空
+func splitIdRange[T any](datasource Datasource, meta *TableMeta[T], m Matcher, options *ScanOptions) ([]ScanRange, error) {
+	minId, maxId, found, err := queryIdRange(datasource, meta, m, options)
+	if err != nil || !found {
+		return nil, err
+	}
+	start := minId - 1
+	if options.StartId > start {
+		start = options.StartId
+	}
+	if start >= maxId {
+		return nil, nil
+	}
+	step := (maxId - start + int64(options.Workers) - 1) / int64(options.Workers)
+	var ranges []ScanRange
+	for i := 0; i < options.Workers; i++ {
+		from := start + int64(i)*step
+		if from >= maxId {
+			break
+		}
+		to := from + step
+		if to > maxId {
+			to = maxId
+		}
+		ranges = append(ranges, ScanRange{from, to})
+	}
+	return ranges, nil
+}
+
+// scanIdRange 扫描 (from, to] 区间的数据，hasUpper 为 false 表示没有上限, stopped 被设置时提前退出
+func scanIdRange[T any](datasource Datasource, meta *TableMeta[T], m Matcher, chunkSize int, options *ScanOptions, worker int, from, to int64, hasUpper bool, stopped *int32, handler ChunkHandler[T]) error {
+	idColumn := meta.idColumn()
+	lastId := from
+	for {
+		if stopped != nil && atomic.LoadInt32(stopped) == 1 {
+			return nil
+		}
+		cm := NewMatcher().Gt(idColumn, lastId)
+		if hasUpper {
+			cm.Lte(idColumn, to)
+		}
+		if m != nil {
+			cm.Add(m)
+		}
+		var count int
+		var chunkLastId int64
+		err := AutoTrans(func() (*TransContext, error) {
+			return NewTransContext(datasource, options.TxRequest, options.TraceId)
+		}, func(tc *TransContext) error {
+			chunk, err := QueryPageListMatcher(tc, cm, meta, NewPager(chunkSize, 1), NewOrder(idColumn))
+			if err != nil || len(chunk) == 0 {
+				return err
+			}
+			count = len(chunk)
+			if chunkLastId, err = idOfIns(meta, chunk[count-1]); err != nil {
+				return err
+			}
+			return handler(tc, chunk)
+		})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		lastId = chunkLastId
+		if options.Checkpoint != nil {
+			options.Checkpoint(worker, lastId)
+		}
+		if count < chunkSize {
+			return nil
+		}
+		if options.Throttle > 0 {
+			time.Sleep(options.Throttle)
+		}
+	}
+}
+
+func queryIdRange[T any](datasource Datasource, meta *TableMeta[T], m Matcher, options *ScanOptions) (int64, int64, bool, error) {
+	if err := checkMatcherColumns(meta, m); err != nil {
+		return 0, 0, false, err
+	}
+	idColumn := meta.idColumn()
+	cm := NewMatcher()
+	if options.HasEndId {
+		cm.Lte(idColumn, options.EndId)
+	}
+	if m != nil {
+		cm.Add(m)
+	}
+	var minId, maxId sql.NullInt64
+	err := AutoTrans(func() (*TransContext, error) {
+		return NewTransContext(datasource, txrequest.RequestNone, options.TraceId)
+	}, func(tc *TransContext) error {
+		stmt := "select min(" + idColumn + "),max(" + idColumn + ") from " + GetTableName(tc.ctx, meta)
+		condi, args, err := cm.ToSQL(nil)
+		if err != nil {
+			return err
+		}
+		if condi != "" {
+			stmt += " where " + condi
+		}
+		return queryRowsCore(tc, stmt, args, func(rows *sql.Rows) error {
+			if !rows.Next() {
+				return nil
+			}
+			return rows.Scan(&minId, &maxId)
+		})
+	})
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return minId.Int64, maxId.Int64, minId.Valid && maxId.Valid, nil
+}
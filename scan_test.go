@@ -0,0 +1,108 @@
+package daog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFakeScanDatasource 返回 id 为 1..maxId 的 filter_sample 表，按 id > ? and id <= ? 条件及 limit 返回数据
+func newFakeScanDatasource(t *testing.T, db *fakeDB, maxId *int64) Datasource {
+	db.respond = func(query string, args []any) ([]string, [][]driver.Value) {
+		if strings.HasPrefix(query, "select min(id),max(id)") {
+			return []string{"min", "max"}, [][]driver.Value{{int64(1), *maxId}}
+		}
+		var size int
+		limit := query[strings.LastIndex(query, ",")+1:]
+		for _, c := range limit {
+			size = size*10 + int(c-'0')
+		}
+		var rows [][]driver.Value
+		for id := args[0].(int64) + 1; id <= args[1].(int64) && id <= *maxId && len(rows) < size; id++ {
+			rows = append(rows, []driver.Value{id, "", int64(0), time.Time{}})
+		}
+		return []string{"id", "name", "status", "create_at"}, rows
+	}
+	sqlDB := sql.OpenDB(db)
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+	return &singleDatasource{db: sqlDB, getConnTimeout: time.Second}
+}
+
+func TestScanResumeWorkerRanges(t *testing.T) {
+	maxId := int64(10)
+	db := &fakeDB{}
+	ds := newFakeScanDatasource(t, db, &maxId)
+
+	var mu sync.Mutex
+	var handled []int64
+	var ranges []ScanRange
+	checkpoints := map[int]int64{}
+	options := &ScanOptions{
+		Workers: 2,
+		OnSplit: func(r []ScanRange) {
+			ranges = append(ranges, r...)
+		},
+		Checkpoint: func(worker int, lastId int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			checkpoints[worker] = lastId
+		},
+	}
+	failed := errors.New("fail on 9")
+	handler := func(failOn int64) ChunkHandler[filterSample] {
+		return func(tc *TransContext, chunk []*filterSample) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, ins := range chunk {
+				if ins.Id == failOn {
+					return failed
+				}
+			}
+			for _, ins := range chunk {
+				handled = append(handled, ins.Id)
+			}
+			return nil
+		}
+	}
+	err := ScanByPrimaryKeyChunksWithOptions(ds, filterSampleMeta, nil, 3, options, handler(9))
+	if err != failed {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ranges, []ScanRange{{0, 5}, {5, 10}}) {
+		t.Fatal(ranges)
+	}
+
+	// 续扫时新增的数据不在原始区间内，不会被扫描
+	maxId = 12
+	resume := make([]ScanRange, len(ranges))
+	for i, r := range ranges {
+		resume[i] = r
+		if lastId, ok := checkpoints[i]; ok {
+			resume[i].From = lastId
+		}
+	}
+	queries := len(db.queries)
+	options.Ranges = resume
+	if err = ScanByPrimaryKeyChunksWithOptions(ds, filterSampleMeta, nil, 3, options, handler(0)); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range db.queries[queries:] {
+		if strings.HasPrefix(q, "select min(") {
+			t.Error("resume should not split again", q)
+		}
+	}
+	sort.Slice(handled, func(i, j int) bool {
+		return handled[i] < handled[j]
+	})
+	if !reflect.DeepEqual(handled, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Error(handled)
+	}
+}
@@ -0,0 +1,212 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package daog
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/rolandhe/daog/ttypes"
+	"strconv"
+	"time"
+)
+
+// nullableScanner 包装一个 field 指针，读取到 NULL 时不修改 field 并记录下来，否则把值转换后赋值给 field，
+// 用于 left join 时右表可能整行为 NULL 的场景
+type nullableScanner struct {
+	dest any
+	null bool
+}
+
+func (ns *nullableScanner) Scan(src any) error {
+	if src == nil {
+		ns.null = true
+		return nil
+	}
+	ns.null = false
+	return assignScanValue(ns.dest, src)
+}
+
+// assignScanValue 把从 driver 读取到的 src 转换并赋值给 dest 指针，支持 compile 生成的表实体中常用的 field 类型，
+// 与 database/sql 的转换规则基本一致，但不使用反射
+func assignScanValue(dest any, src any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+	var err error
+	switch d := dest.(type) {
+	case *any:
+		if b, ok := src.([]byte); ok {
+			src = append([]byte(nil), b...)
+		}
+		*d = src
+		return nil
+	case *string:
+		*d, err = scanValueString(src)
+		return err
+	case *[]byte:
+		switch s := src.(type) {
+		case []byte:
+			*d = append([]byte(nil), s...)
+			return nil
+		case string:
+			*d = []byte(s)
+			return nil
+		}
+	case *int64:
+		*d, err = scanValueInt(src, 64)
+		return err
+	case *int32:
+		v, err := scanValueInt(src, 32)
+		*d = int32(v)
+		return err
+	case *int16:
+		v, err := scanValueInt(src, 16)
+		*d = int16(v)
+		return err
+	case *int8:
+		v, err := scanValueInt(src, 8)
+		*d = int8(v)
+		return err
+	case *int:
+		v, err := scanValueInt(src, strconv.IntSize)
+		*d = int(v)
+		return err
+	case *uint64:
+		*d, err = scanValueUint(src, 64)
+		return err
+	case *uint32:
+		v, err := scanValueUint(src, 32)
+		*d = uint32(v)
+		return err
+	case *uint16:
+		v, err := scanValueUint(src, 16)
+		*d = uint16(v)
+		return err
+	case *uint8:
+		v, err := scanValueUint(src, 8)
+		*d = uint8(v)
+		return err
+	case *uint:
+		v, err := scanValueUint(src, strconv.IntSize)
+		*d = uint(v)
+		return err
+	case *float64:
+		*d, err = scanValueFloat(src, 64)
+		return err
+	case *float32:
+		v, err := scanValueFloat(src, 32)
+		*d = float32(v)
+		return err
+	case *bool:
+		if b, ok := src.(bool); ok {
+			*d = b
+			return nil
+		}
+		s, err := scanValueString(src)
+		if err != nil {
+			return err
+		}
+		*d, err = strconv.ParseBool(s)
+		return err
+	case *time.Time:
+		*d, err = scanValueTime(src)
+		return err
+	case *ttypes.NormalDatetime:
+		t, err := scanValueTime(src)
+		*d = ttypes.NormalDatetime(t)
+		return err
+	case *ttypes.NormalDate:
+		t, err := scanValueTime(src)
+		*d = ttypes.NormalDate(t)
+		return err
+	}
+	return fmt.Errorf("unsupported scan, storing driver.Value type %T into type %T", src, dest)
+}
+
+func scanValueString(src any) (string, error) {
+	switch s := src.(type) {
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	case int64:
+		return strconv.FormatInt(s, 10), nil
+	case uint64:
+		return strconv.FormatUint(s, 10), nil
+	case float64:
+		return strconv.FormatFloat(s, 'g', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(s), 'g', -1, 32), nil
+	case bool:
+		return strconv.FormatBool(s), nil
+	case time.Time:
+		return s.Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("unsupported scan, converting driver.Value type %T to string", src)
+}
+
+func scanValueInt(src any, bitSize int) (int64, error) {
+	switch s := src.(type) {
+	case int64:
+		if bitSize < 64 && (s < -1<<(bitSize-1) || s > 1<<(bitSize-1)-1) {
+			return 0, fmt.Errorf("converting driver.Value %d to a %d-bit int: value out of range", s, bitSize)
+		}
+		return s, nil
+	case bool:
+		if s {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	str, err := scanValueString(src)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(str, 10, bitSize)
+}
+
+func scanValueUint(src any, bitSize int) (uint64, error) {
+	if s, ok := src.(uint64); ok {
+		if bitSize < 64 && s > 1<<bitSize-1 {
+			return 0, fmt.Errorf("converting driver.Value %d to a %d-bit uint: value out of range", s, bitSize)
+		}
+		return s, nil
+	}
+	str, err := scanValueString(src)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(str, 10, bitSize)
+}
+
+func scanValueFloat(src any, bitSize int) (float64, error) {
+	switch s := src.(type) {
+	case float64:
+		return s, nil
+	case float32:
+		return float64(s), nil
+	case int64:
+		return float64(s), nil
+	}
+	str, err := scanValueString(src)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(str, bitSize)
+}
+
+// scanValueTime 支持 driver 开启 parseTime 时返回的 time.Time，也支持未开启时返回的 []byte
+func scanValueTime(src any) (time.Time, error) {
+	if t, ok := src.(time.Time); ok {
+		return t, nil
+	}
+	s, err := scanValueString(src)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(s) <= len("2006-01-02") {
+		return time.ParseInLocation("2006-01-02", s, time.Local)
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05.999999", s, time.Local)
+}
@@ -0,0 +1,98 @@
+package daog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rolandhe/daog/ttypes"
+	"github.com/shopspring/decimal"
+)
+
+func TestAssignScanValue(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	uuid, _ := ttypes.ParseUUID("0f8fad5b-d9cb-469f-a165-70867728950e")
+	cases := []struct {
+		dest     any
+		src      any
+		expected any
+	}{
+		{new(string), []byte("abc"), "abc"},
+		{new(string), int64(12), "12"},
+		{new([]byte), []byte("abc"), []byte("abc")},
+		{new([]byte), "abc", []byte("abc")},
+		{new(any), []byte("abc"), []byte("abc")},
+		{new(int64), int64(-5), int64(-5)},
+		{new(int64), []byte("-5"), int64(-5)},
+		{new(int32), int64(7), int32(7)},
+		{new(int16), []byte("7"), int16(7)},
+		{new(int8), int64(-7), int8(-7)},
+		{new(int), true, 1},
+		{new(uint64), uint64(18446744073709551615), uint64(18446744073709551615)},
+		{new(uint64), []byte("18446744073709551615"), uint64(18446744073709551615)},
+		{new(uint32), int64(7), uint32(7)},
+		{new(uint16), []byte("7"), uint16(7)},
+		{new(uint8), int64(255), uint8(255)},
+		{new(uint), int64(7), uint(7)},
+		{new(float64), []byte("1.5"), 1.5},
+		{new(float64), int64(2), 2.0},
+		{new(float32), float64(1.5), float32(1.5)},
+		{new(bool), int64(1), true},
+		{new(bool), []byte("0"), false},
+		{new(time.Time), at, at},
+		{new(time.Time), []byte("2024-01-02 03:04:05"), at},
+		{new(ttypes.NormalDatetime), []byte("2024-01-02 03:04:05"), ttypes.NormalDatetime(at)},
+		{new(ttypes.NormalDatetime), at, ttypes.NormalDatetime(at)},
+		{new(ttypes.NormalDate), []byte("2024-01-02"), ttypes.NormalDate(day)},
+		{new(ttypes.NilableString), []byte("abc"), *ttypes.FromString("abc")},
+		{new(ttypes.NilableDatetime), at, *ttypes.FromDatetime(at)},
+		{new(ttypes.NilableDate), day, *ttypes.FromDate(day)},
+		{new(ttypes.UUID), uuid[:], uuid},
+		{new(decimal.Decimal), []byte("12.50"), decimal.RequireFromString("12.50")},
+	}
+	for i, c := range cases {
+		if err := assignScanValue(c.dest, c.src); err != nil {
+			t.Errorf("case %d %T: %v", i, c.dest, err)
+			continue
+		}
+		got := reflect.ValueOf(c.dest).Elem().Interface()
+		if d, ok := got.(decimal.Decimal); ok {
+			if !d.Equal(c.expected.(decimal.Decimal)) {
+				t.Errorf("case %d: %v", i, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d %T: %v", i, c.dest, got)
+		}
+	}
+
+	invalid := []struct {
+		dest any
+		src  any
+	}{
+		{new(int8), int64(128)},
+		{new(uint8), int64(-1)},
+		{new(int32), int64(1) << 31},
+		{new(uint16), uint64(1) << 16},
+		{new(uint64), int64(-1)},
+		{new(int64), []byte("abc")},
+		{new(bool), []byte("yes")},
+		{new(time.Time), []byte("2024/01/02")},
+		{new(struct{}), int64(1)},
+	}
+	for i, c := range invalid {
+		if err := assignScanValue(c.dest, c.src); err == nil {
+			t.Errorf("invalid case %d %T should fail", i, c.dest)
+		}
+	}
+
+	ns := &nullableScanner{dest: new(int64)}
+	if err := ns.Scan(nil); err != nil || !ns.null {
+		t.Error("nil should be recorded as null")
+	}
+	if err := ns.Scan(int64(3)); err != nil || ns.null || *ns.dest.(*int64) != 3 {
+		t.Error(ns)
+	}
+}
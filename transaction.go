@@ -234,6 +234,8 @@ type TransContext struct {
 	ctx       context.Context
 	LogSQL    bool
 	ExtInfo   map[string]any
+	// dirtyCaches 事务内修改过的开启了缓存的表，事务提交成功后使其缓存失效
+	dirtyCaches map[string]Cache
 }
 
 // CompleteWithPanic 事务最终完成，可能是提交，也可能是会管，生命周期结束.
@@ -273,6 +275,8 @@ func (tc *TransContext) Complete(e error) {
 		}
 		if err != nil {
 			GLogger.Error(tc.ctx, err)
+		} else if e == nil {
+			tc.invalidateDirtyCaches()
 		}
 		closeConn(tc)
 		tc.status = tcStatusInvalid
@@ -0,0 +1,116 @@
+// A quickly mysql access component.
+// Copyright 2023 The daog Authors. All rights reserved.
+
+package ttypes
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// UUID 以 binary(16) 存储的 uuid 类型，json 及 fmt.Stringer 输出为 xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx 格式的字符串，
+// 实现 driver.Valuer, sql.Scanner, json.Unmarshaler, json.Marshaler 接口，从 char(36) 字段中读取时也可以正确解析
+type UUID [16]byte
+
+// NewUUID 生成一个随机的 version 4 uuid
+func NewUUID() (UUID, error) {
+	var u UUID
+	if _, err := rand.Read(u[:]); err != nil {
+		return u, err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return u, nil
+}
+
+// ParseUUID 解析字符串形式的 uuid，支持带 - 的36位格式和不带 - 的32位格式
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, errors.New("invalid uuid: " + s)
+		}
+		s = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return u, errors.New("invalid uuid: " + s)
+	}
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, errors.New("invalid uuid: " + s)
+	}
+	return u, nil
+}
+
+// IsZero 是否是全0的 uuid
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// String 实现 fmt.Stringer 接口
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// Value 实现 driver.Valuer，以16字节的二进制存储
+func (u UUID) Value() (driver.Value, error) {
+	return u[:], nil
+}
+
+// Scan 实现 sql.Scanner，支持 binary(16) 以及字符串形式的 uuid，NULL 被读取为全0的 uuid
+func (u *UUID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*u = UUID{}
+		return nil
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.parseText(string(v))
+	case string:
+		return u.parseText(v)
+	default:
+		return fmt.Errorf("can't scan %T into UUID", src)
+	}
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler，值必须是带双引号的 uuid 字符串或者 null
+func (u *UUID) UnmarshalJSON(b []byte) error {
+	if len(b) == 0 || bytes.Equal(b, nullJsonValue) {
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return errors.New("invalid uuid json: " + string(b))
+	}
+	return u.parseText(string(b[1 : len(b)-1]))
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (u UUID) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + u.String() + `"`), nil
+}
+
+func (u *UUID) parseText(s string) error {
+	parsed, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
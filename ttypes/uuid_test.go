@@ -0,0 +1,80 @@
+package ttypes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseUUID(t *testing.T) {
+	const s = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	u, err := ParseUUID(s)
+	if err != nil || u.String() != s {
+		t.Error(u, err)
+	}
+	if u2, err := ParseUUID("0f8fad5bd9cb469fa16570867728950e"); err != nil || u2 != u {
+		t.Error(u2, err)
+	}
+	for _, bad := range []string{"", "0f8fad5b-d9cb-469f-a165-70867728950", "0f8fad5bxd9cb-469f-a165-70867728950e", "zf8fad5b-d9cb-469f-a165-70867728950e"} {
+		if _, err = ParseUUID(bad); err == nil {
+			t.Error("should fail:", bad)
+		}
+	}
+
+	n, err := NewUUID()
+	if err != nil || n.IsZero() || n[6]>>4 != 4 || n[8]>>6 != 2 {
+		t.Error(n, err)
+	}
+	if parsed, _ := ParseUUID(n.String()); parsed != n {
+		t.Error("string round trip", n)
+	}
+}
+
+func TestUUIDScan(t *testing.T) {
+	want, _ := ParseUUID("0f8fad5b-d9cb-469f-a165-70867728950e")
+	var u UUID
+	if err := u.Scan(want[:]); err != nil || u != want {
+		t.Error(u, err)
+	}
+	u = UUID{}
+	if err := u.Scan([]byte(want.String())); err != nil || u != want {
+		t.Error(u, err)
+	}
+	u = UUID{}
+	if err := u.Scan(want.String()); err != nil || u != want {
+		t.Error(u, err)
+	}
+	if err := u.Scan(nil); err != nil || !u.IsZero() {
+		t.Error("nil should zero the uuid", u, err)
+	}
+	if err := u.Scan(int64(1)); err == nil {
+		t.Error("int should fail")
+	}
+	if v, _ := want.Value(); len(v.([]byte)) != 16 {
+		t.Error(v)
+	}
+}
+
+func TestUUIDJson(t *testing.T) {
+	type holder struct {
+		Id UUID `json:"id"`
+	}
+	want, _ := ParseUUID("0f8fad5b-d9cb-469f-a165-70867728950e")
+	data, err := json.Marshal(&holder{want})
+	if err != nil || string(data) != `{"id":"0f8fad5b-d9cb-469f-a165-70867728950e"}` {
+		t.Error(string(data), err)
+	}
+	h := &holder{}
+	if err = json.Unmarshal(data, h); err != nil || h.Id != want {
+		t.Error(h.Id, err)
+	}
+	if err = json.Unmarshal([]byte(`{"id":null}`), h); err != nil || h.Id != want {
+		t.Error("null should keep value", h.Id, err)
+	}
+
+	var u UUID
+	for _, bad := range []string{`""0f8fad5b-d9cb-469f-a165-70867728950e""`, `0f8fad5b-d9cb-469f-a165-70867728950e`, `"0f8fad5b-d9cb-469f-a165-70867728950e`, `"`} {
+		if err = u.UnmarshalJSON([]byte(bad)); err == nil {
+			t.Error("should fail:", bad)
+		}
+	}
+}
@@ -18,7 +18,7 @@ func (fe * fieldExtractor[T]) Extract(fieldName string) any{
 	return fe.meta.LookupFieldFunc(fieldName,fe.ins,true)
 }
 
-// Update 更新一条数据，把 *T类型的 ins 更新到数据，ins中的主键必须被设置
+// Update 更新一条数据，把 *T类型的 ins 更新到数据，ins中的主键必须被设置，主键字段由 TableMeta.PrimaryKeys 确定，支持联合主键
 // meta 表的元数据，由compile编译生成，比如  GroupInfo.GroupInfoMeta
 // 返回值是 更新的数据的条数，是0或者1
 func Update[T any](tc *TransContext, ins *T, meta *TableMeta[T]) (int64, error) {
@@ -28,13 +28,10 @@ func Update[T any](tc *TransContext, ins *T, meta *TableMeta[T]) (int64, error)
 			return 0, err
 		}
 	}
-	idValue := meta.LookupFieldFunc(TableIdColumnName, ins, false)
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
+	m, err := keyMatcher(meta, insKeyValues(meta, ins))
+	if err != nil {
+		return 0, err
 	}
-	m.Eq(fieldId, idValue)
 
 	if err := auoFillField(tc,ins,meta);err != nil{
 		return 0, err
@@ -45,7 +42,12 @@ func Update[T any](tc *TransContext, ins *T, meta *TableMeta[T]) (int64, error)
 		return 0, err
 	}
 
-	return execSQLCore(tc, sql, args)
+	affect, err := execSQLCore(tc, sql, args)
+	if err != nil {
+		return 0, err
+	}
+	markTableModified(tc, meta)
+	return affect, nil
 }
 
 // UpdateList 更新多条数据，把多个 *T类型的 ins 更新到数据，每个ins中的主键必须被设置
@@ -70,30 +72,21 @@ func UpdateList[T any](tc *TransContext, insList []*T, meta *TableMeta[T]) (int6
 
 // UpdateById 根据主键修改一条记录，需要修改的字段值通过 Modifier 指定
 func UpdateById[T any](tc *TransContext, modifier Modifier, id int64, meta *TableMeta[T]) (int64, error) {
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
-	}
-	m.Eq(fieldId, id)
+	m := NewMatcher().Eq(meta.idColumn(), id)
 	return UpdateByModifier(tc, modifier, m, meta)
 }
 
 // UpdateByIds 根据多个主键修改多条记录，需要修改的字段值通过 Modifier 指定，表达 update table set a=?,b=? where id in(xx,xx)的语义
+// 主键个数超过 MaxInSize 时，会在同一个 tc 内分批执行多条 update 语句，返回值是各批修改记录数之和，某一批失败时的返回值见 MaxInSize
 func UpdateByIds[T any](tc *TransContext, modifier Modifier, ids []int64, meta *TableMeta[T]) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
-	m := NewMatcher()
-	fieldId := TableIdColumnName
-	if meta.AutoColumn != "" {
-		fieldId = meta.AutoColumn
-	}
-	m.In(fieldId, ConvertToAnySlice(ids))
-	return UpdateByModifier(tc, modifier, m, meta)
+	return execInChunks(tc, ids, MaxInSize, func(chunk []int64) (int64, error) {
+		return UpdateByModifier(tc, modifier, NewMatcher().In(meta.idColumn(), ConvertToAnySlice(chunk)), meta)
+	})
 }
 
-
 // UpdateByModifier 根据Matcher条件修改多条记录，需要修改的字段值通过 Modifier 指定，表达 update table set a=?,b=? where uid=? and status=0 的类似语义
 func UpdateByModifier[T any](tc *TransContext, modifier Modifier, matcher Matcher, meta *TableMeta[T]) (int64, error) {
 	if AddNewModifyFieldBeforeUpdate != nil{
@@ -115,10 +108,15 @@ func UpdateByModifier[T any](tc *TransContext, modifier Modifier, matcher Matche
 	if sql == "" {
 		return 0, nil
 	}
-	return execSQLCore(tc, sql, args)
+	affect, err := execSQLCore(tc, sql, args)
+	if err != nil {
+		return 0, err
+	}
+	markTableModified(tc, meta)
+	return affect, nil
 }
 
-// ExecRawSQL 执行原生的sql
+// ExecRawSQL 执行原生的sql，它不知道修改了哪些表，不会使 TableMeta.Cache 失效
 func ExecRawSQL(tc *TransContext, sql string, args ...any) (int64, error) {
 	return execSQLCore(tc, sql, args)
 }